@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 Ruslan Mayer
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rmay1er/jgeo-excel/internal/app"
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// choroplethCmd представляет команду choropleth
+var choroplethCmd = &cobra.Command{
+	Use:   "choropleth",
+	Short: "Раскрасить полигоны GeoJSON по показателям из Excel",
+	Long: `Команда choropleth присоединяет строки таблицы Excel к полигонам базового GeoJSON файла
+по ключу (например, названию района), переносит выбранные столбцы в свойства полигонов
+и задаёт заливку (fill, fill-opacity) по числовому показателю.
+
+Классы показателя строятся методом equal_interval (равные интервалы), quantile (квантили)
+или manual (заданные пороги). Параметры задаются в разделе choropleth конфигурации.
+
+Example:
+  jgeo-excel choropleth --config districts.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return fmt.Errorf("ошибка при получении флага --config: %w", err)
+		}
+
+		fmt.Printf("📂 Загружаю конфигурацию из: %s\n", configPath)
+		cfg, err := config.LoadChoroplethConfig(configPath)
+		if err != nil {
+			return fmt.Errorf("❌ ошибка при загрузке конфигурации: %w", err)
+		}
+
+		ch := cfg.Choropleth
+		fmt.Println("✅ Конфигурация загружена успешно")
+		fmt.Printf("  📊 Excel файл: %s (лист: %s), ключ: %s\n", cfg.Excel.File, cfg.Excel.Sheet, cfg.Excel.Columns.ID)
+		fmt.Printf("  🗺️  GeoJSON: %s → %s, ключ полигона: %s\n", cfg.Geojson.Input, cfg.Geojson.Output, ch.JoinOn)
+		fmt.Printf("  📈 Показатель: %s, метод: %s, классов: %d\n", ch.Value, ch.Method, ch.Classes)
+
+		application, err := app.NewChoroplethApp(cfg)
+		if err != nil {
+			return fmt.Errorf("❌ ошибка при инициализации приложения: %w", err)
+		}
+		defer application.Close()
+
+		if err := application.Process(); err != nil {
+			return fmt.Errorf("❌ ошибка при обработке: %w", err)
+		}
+
+		fmt.Printf("\n✅ Успешно! Результат сохранен в: %s\n", cfg.Geojson.Output)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(choroplethCmd)
+
+	choroplethCmd.Flags().StringP("config", "c", "", "Путь к конфигурационному YAML файлу (обязателен)")
+	choroplethCmd.MarkFlagRequired("config")
+}
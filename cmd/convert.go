@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 Ruslan Mayer
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rmay1er/jgeo-excel/internal/app"
+	"github.com/rmay1er/jgeo-excel/internal/processors"
+	gpkg "github.com/rmay1er/jgeo-excel/internal/writers/geopackage"
+	"github.com/spf13/cobra"
+)
+
+// convertCmd представляет команду convert
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Преобразовать объекты между форматами (GeoJSON, KML, KMZ, GPX, Shapefile, GeoPackage, Excel)",
+	Long: `Команда convert читает объекты из одного формата и записывает в другой.
+Формат определяется по расширению файла:
+  .geojson, .json   GeoJSON
+  .kml, .kmz        KML (Google Earth, OruxMaps); папки Folder - свойство folder
+  .gpx              GPX (Garmin): путевые точки wpt, маршруты rte и треки trk
+  .shp, .zip        шейп-файл ESRI или zip-архив с ним; координаты из .prj переводятся в WGS 84
+  .gpkg             GeoPackage: слой для каждого типа геометрии или для каждого значения
+                    свойства --layer-property; при чтении - все слои с переводом в WGS 84
+  .xlsx             книга Excel в разметке to-excel (только для записи)
+
+Название, описание, свойства и оформление (marker-color, stroke, fill и т.д.) переносятся;
+в GPX оформление и полигоны не записываются, в шейп-файл - оформление. В GeoPackage оформление и свойства становятся столбцами слоя.
+
+Example:
+  jgeo-excel convert --input точки.kmz --output точки.geojson
+  jgeo-excel convert --input карта.geojson --output карта.kmz
+  jgeo-excel convert --input Track_2025-06-01.gpx --output трек.geojson
+  jgeo-excel convert --input границы.zip --output границы.geojson
+  jgeo-excel convert --input объекты.geojson --output проект.gpkg --layer-property category`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		in, _ := cmd.Flags().GetString("input")
+		out, _ := cmd.Flags().GetString("output")
+
+		reader, err := app.NewFileReader(in)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🔁 %s (%s) → %s (%s)\n", in, app.FileFormat(in), out, app.FileFormat(out))
+
+		writer := app.NewFileWriter(out)
+		if layerProperty, _ := cmd.Flags().GetString("layer-property"); layerProperty != "" {
+			gpkgWriter, ok := writer.(*gpkg.GeoPackageWriter)
+			if !ok {
+				return fmt.Errorf("--layer-property применяется только к выходному файлу .gpkg")
+			}
+			gpkgWriter.SetLayerProperty(layerProperty)
+		}
+		processor := processors.NewMarksProcessor(reader, writer)
+		application := app.NewJGeoApp(processor, writer)
+		defer application.Close()
+
+		return application.ProcessToFile(out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().StringP("input", "i", "", "Путь к исходному файлу (GeoJSON, KML, KMZ, GPX, .shp, .zip или .gpkg)")
+	convertCmd.Flags().StringP("output", "o", "", "Путь к итоговому файлу (GeoJSON, KML, KMZ, GPX, .shp, .zip, .gpkg или xlsx)")
+	convertCmd.Flags().String("layer-property", "", "Свойство, по значению которого объекты раскладываются по слоям GeoPackage")
+	convertCmd.MarkFlagRequired("input")
+	convertCmd.MarkFlagRequired("output")
+}
@@ -6,6 +6,10 @@ package cmd
 import (
 	"fmt"
 
+	geojson "github.com/paulmach/go.geojson"
+	"github.com/rmay1er/jgeo-excel/internal/filter"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/spatial"
 	gjs "github.com/rmay1er/jgeo-excel/internal/writers/geojson"
 	"github.com/spf13/cobra"
 )
@@ -13,18 +17,47 @@ import (
 // remove-marksCmd представляет команду remove-marks
 var removeMarksCmd = &cobra.Command{
 	Use:   "remove-marks",
-	Short: "Удалить все точки из GeoJSON файла",
-	Long: `Удалить все точки из GeoJSON файла, оставив коллекцию поллигонов.
+	Short: "Удалить точки из GeoJSON файла",
+	Long: `Удалить точки из GeoJSON файла, оставив коллекцию поллигонов.
+
+Без условий удаляются все точки. Условия объединяются через "и":
+  --where ключ=значение   свойство равно значению (без учёта регистра)
+  --where ключ~подстрока  свойство содержит подстроку
+  --inside условие        точка внутри полигона, выбранного условием
+  --outside условие       точка вне полигона, выбранного условием
+  --run идентификатор     точка добавлена запуском to-geojson с этим идентификатором
 
 Пример:
-	excel-cords-to-geojson remove-marks --file путь/к/файлу.geojson`,
+	excel-cords-to-geojson remove-marks --file путь/к/файлу.geojson
+	excel-cords-to-geojson remove-marks -f карта.geojson --where marker-color=#0000FF --dry-run
+	excel-cords-to-geojson remove-marks -f карта.geojson --where 'description~закрыт' -o очищено.geojson
+	excel-cords-to-geojson remove-marks -f карта.geojson --outside "iconCaption=Зал 1"
+	excel-cords-to-geojson remove-marks -f карта.geojson --run 20250301-101500`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filePath, _ := cmd.Flags().GetString("file")
 		if filePath == "" {
 			return fmt.Errorf("ошибка: требуется флаг --file")
 		}
+		outputPath, _ := cmd.Flags().GetString("output")
+		if outputPath == "" {
+			outputPath = filePath
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		where, _ := cmd.Flags().GetStringArray("where")
+		inside, _ := cmd.Flags().GetString("inside")
+		outside, _ := cmd.Flags().GetString("outside")
+		runID, _ := cmd.Flags().GetString("run")
 
-		fmt.Printf("🗑️  Удаляю все точки из: %s\n", filePath)
+		if inside != "" && outside != "" {
+			return fmt.Errorf("❌ флаги --inside и --outside нельзя использовать вместе")
+		}
+		conditions, err := filter.ParseAll(where)
+		if err != nil {
+			return fmt.Errorf("❌ ошибка в условии --where: %w", err)
+		}
+		if runID != "" {
+			conditions = append(conditions, filter.Condition{Key: gjs.ImportRunProperty, Op: filter.OpEquals, Value: runID})
+		}
 
 		// Создаем GeoJSON writer
 		writer, err := gjs.NewGeojsonWriter(filePath)
@@ -33,27 +66,119 @@ var removeMarksCmd = &cobra.Command{
 		}
 		defer writer.Close()
 
-		// Очищаем все точки из файла
-		if err := writer.RemoveAllPoints(); err != nil {
-			return fmt.Errorf("❌ ошибка при удалении точек: %w", err)
+		var area []models.Geometry
+		areaExpr := inside + outside
+		if areaExpr != "" {
+			area, err = findArea(writer, areaExpr)
+			if err != nil {
+				return fmt.Errorf("❌ %w", err)
+			}
+		}
+
+		if len(conditions) == 0 && area == nil {
+			fmt.Printf("🗑️  Удаляю все точки из: %s\n", filePath)
+		} else {
+			fmt.Printf("🗑️  Удаляю точки из: %s\n", filePath)
+			for _, cond := range conditions {
+				fmt.Printf("  🔎 Условие: %s\n", cond)
+			}
+			if inside != "" {
+				fmt.Printf("  🔷 Внутри полигона: %s (найдено %d)\n", inside, len(area))
+			}
+			if outside != "" {
+				fmt.Printf("  🔷 Вне полигона: %s (найдено %d)\n", outside, len(area))
+			}
+		}
+
+		removed := writer.RemovePoints(func(feature *geojson.Feature) bool {
+			if !filter.MatchAll(feature, conditions) {
+				return false
+			}
+			if area == nil {
+				return true
+			}
+			return inArea(area, feature.Geometry.Point) == (inside != "")
+		})
+
+		if dryRun {
+			fmt.Printf("🔍 Пробный запуск: будет удалено точек: %d\n", len(removed))
+			for _, feature := range removed {
+				fmt.Printf("  - %s\n", describeFeature(feature))
+			}
+			return nil
 		}
 
-		// Сохраняем пустой GeoJSON файл
-		if err := writer.Save(filePath); err != nil {
+		// Сохраняем GeoJSON файл
+		if err := writer.Save(outputPath); err != nil {
 			return fmt.Errorf("❌ ошибка сохранения GeoJSON файла: %w", err)
 		}
 
-		fmt.Printf("✅ Все точки удалены из %s\n", filePath)
+		fmt.Printf("✅ Удалено точек: %d, результат сохранен в %s\n", len(removed), outputPath)
 		return nil
 	},
 }
 
+// findArea находит полигоны, выбранные условием --inside или --outside
+func findArea(writer *gjs.GeojsonWriter, expr string) ([]models.Geometry, error) {
+	cond, err := filter.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка в условии выбора полигона: %w", err)
+	}
+
+	var area []models.Geometry
+	for _, feature := range writer.Features(cond.Match) {
+		geometry, err := models.GeometryFromGeoJSON(feature.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		if spatial.IsPolygonal(geometry) {
+			area = append(area, geometry)
+		}
+	}
+	if len(area) == 0 {
+		return nil, fmt.Errorf("не найден полигон по условию '%s'", expr)
+	}
+	return area, nil
+}
+
+// inArea сообщает, лежит ли точка внутри хотя бы одного из полигонов
+func inArea(area []models.Geometry, pos []float64) bool {
+	for _, geometry := range area {
+		if spatial.Contains(geometry, pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeFeature кратко описывает точку для вывода в пробном запуске
+func describeFeature(feature *geojson.Feature) string {
+	name, _ := feature.Properties["iconCaption"].(string)
+	if name == "" {
+		name = "(без названия)"
+	}
+	text := name
+	if pos := feature.Geometry.Point; len(pos) >= 2 {
+		text = fmt.Sprintf("%s [%v, %v]", name, pos[0], pos[1])
+	}
+	if feature.ID != nil {
+		text = fmt.Sprintf("id=%v %s", feature.ID, text)
+	}
+	return text
+}
+
 func init() {
 	rootCmd.AddCommand(removeMarksCmd)
 
 	// Здесь вы определите флаги и настройки конфигурации.
 	removeMarksCmd.Flags().StringP("file", "f", "", "Путь к GeoJSON файлу")
 	removeMarksCmd.MarkFlagRequired("file")
+	removeMarksCmd.Flags().StringP("output", "o", "", "Путь для сохранения результата (по умолчанию перезаписывается --file)")
+	removeMarksCmd.Flags().Bool("dry-run", false, "Показать точки, которые будут удалены, не изменяя файл")
+	removeMarksCmd.Flags().StringArrayP("where", "w", nil, "Условие на свойство: ключ=значение или ключ~подстрока (можно указать несколько)")
+	removeMarksCmd.Flags().String("inside", "", "Удалять точки внутри полигона, выбранного условием ключ=значение")
+	removeMarksCmd.Flags().String("outside", "", "Удалять точки вне полигона, выбранного условием ключ=значение")
+	removeMarksCmd.Flags().String("run", "", "Удалять точки, добавленные запуском to-geojson с этим идентификатором")
 
 	// Cobra поддерживает Persistent Flags, которые будут работать для этой команды
 	// и всех подкоманд, например:
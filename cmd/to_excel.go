@@ -4,11 +4,13 @@ Copyright © 2025 Ruslan Mayer
 package cmd
 
 import (
+	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/rmay1er/jgeo-excel/internal/app"
+	"github.com/rmay1er/jgeo-excel/internal/crs"
 	"github.com/rmay1er/jgeo-excel/internal/processors"
-	readers "github.com/rmay1er/jgeo-excel/internal/readers/geojson"
 	writers "github.com/rmay1er/jgeo-excel/internal/writers/excel"
 	"github.com/spf13/cobra"
 )
@@ -16,24 +18,60 @@ import (
 // toExcelCmd представляет команду to-excel
 var toExcelCmd = &cobra.Command{
 	Use:   "to-excel",
-	Short: "Преобразовать информацию из GeoJSON в Excel",
-	Long:  `Команда to-excel читает коллекцию из GeoJSON файла и создаёт из них xlsx.`,
+	Short: "Преобразовать информацию из GeoJSON или KML в Excel",
+	Long: `Команда to-excel читает коллекцию из GeoJSON, KML, KMZ, GPX, шейп-файла (.shp, .zip) или GeoPackage (.gpkg) и создаёт из них xlsx.
+
+Рядом с книгой создаётся конфигурация для обратного преобразования (to-geojson),
+так что книгу можно отредактировать в Excel и собрать GeoJSON заново без потери
+геометрии, оформления и свойств.
+
+Флаг --crs записывает WKT в другой системе координат (например, СК-42 / Гаусс-Крюгер
+для геодезистов); система сохраняется в конфигурации обратного преобразования.
+
+Example:
+  jgeo-excel to-excel --input map.geojson
+  jgeo-excel to-excel --input map.geojson --crs EPSG:28407
+  jgeo-excel to-excel --input points.kmz
+  jgeo-excel to-excel --input garmin.gpx
+  jgeo-excel to-excel --input участки.zip
+  jgeo-excel to-excel --input проект.gpkg
+  jgeo-excel to-geojson --config map.yaml`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		in, _ := cmd.Flags().GetString("input")
 		out, _ := cmd.Flags().GetString("output")
 		if out == "" {
-			out = strings.TrimSuffix(in, ".geojson") + ".xlsx"
+			out = strings.TrimSuffix(in, filepath.Ext(in)) + ".xlsx"
 		}
-		geojsonReader, err := readers.NewGeoJSONReader(in)
-		excelWriter := writers.NewExcelWriter()
-		processor := processors.NewMarksProcessor(geojsonReader, excelWriter)
+		configOut, _ := cmd.Flags().GetString("config-out")
+		if configOut == "" {
+			configOut = strings.TrimSuffix(out, ".xlsx") + ".yaml"
+		}
+		var system *crs.CRS
+		if code, _ := cmd.Flags().GetString("crs"); code != "" {
+			var err error
+			if system, err = crs.Parse(code); err != nil {
+				return err
+			}
+			fmt.Printf("🌐 Система координат WKT: %s\n", system)
+		}
+		reader, err := app.NewFileReader(in)
 		if err != nil {
 			return err
 		}
+		excelWriter := writers.NewExcelWriter()
+		excelWriter.SetCRS(system)
+		processor := processors.NewMarksProcessor(reader, excelWriter)
 		app := app.NewJGeoApp(processor, excelWriter)
 		if err := app.ProcessToExcel(out); err != nil {
 			return err
 		}
+
+		// Конфигурация для обратного преобразования
+		geojsonOut := strings.TrimSuffix(out, ".xlsx") + ".from-excel.geojson"
+		if err := excelWriter.WriteRoundTripConfig(configOut, out, geojsonOut); err != nil {
+			return err
+		}
+		fmt.Printf("🔁 Конфигурация для обратного преобразования: %s\n", configOut)
 		return nil
 	},
 }
@@ -42,7 +80,9 @@ func init() {
 	rootCmd.AddCommand(toExcelCmd)
 
 	// Добавляем флаг для пути к конфигурационному файлу
-	toExcelCmd.Flags().StringP("input", "i", "", "Путь к GeoJson файлу обязателен")
+	toExcelCmd.Flags().StringP("input", "i", "", "Путь к GeoJSON, KML, KMZ, GPX, шейп-файлу (.shp, .zip) или GeoPackage (.gpkg) обязателен")
 	toExcelCmd.Flags().StringP("output", "o", "", "Путь к итоговому файлу, если необходимо")
+	toExcelCmd.Flags().String("crs", "", "Система координат WKT (код EPSG, например EPSG:28407), по умолчанию WGS 84")
+	toExcelCmd.Flags().String("config-out", "", "Путь к конфигурации для обратного преобразования (по умолчанию рядом с xlsx)")
 	toExcelCmd.MarkFlagRequired("input")
 }
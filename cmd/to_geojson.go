@@ -5,9 +5,11 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/rmay1er/jgeo-excel/internal/app"
 	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/crs"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +26,8 @@ var toGeoJsonCmd = &cobra.Command{
 
 Example:
   jgeo-excel to-geojson --config config.yaml
-  jgeo-excel to-geojson -c config.yaml`,
+  jgeo-excel to-geojson -c config.yaml
+  jgeo-excel to-geojson -c config.yaml --run-id auto`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Получаем путь к конфигурационному файлу из флага
 		configPath, err := cmd.Flags().GetString("config")
@@ -36,6 +39,11 @@ Example:
 			return fmt.Errorf("флаг --config обязателен. Используйте: to-geojson --config config.yaml")
 		}
 
+		runID, err := cmd.Flags().GetString("run-id")
+		if err != nil {
+			return fmt.Errorf("ошибка при получении флага --run-id: %w", err)
+		}
+
 		fmt.Printf("📂 Загружаю конфигурацию из: %s\n", configPath)
 
 		// Загружаем конфигурацию
@@ -44,11 +52,51 @@ Example:
 			return fmt.Errorf("❌ ошибка при загрузке конфигурации: %w", err)
 		}
 
+		// Флаг --run-id имеет приоритет над geojson.run_id
+		if runID = strings.TrimSpace(runID); runID != "" {
+			cfg.Geojson.RunID = config.ResolveRunID(runID)
+		}
+
 		fmt.Println("✅ Конфигурация загружена успешно")
-		fmt.Printf("  📊 Excel файл: %s (лист: %s)\n", cfg.Excel.File, cfg.Excel.Sheet)
-		fmt.Printf("  📍 Столбцы: название=%s, описание=%s, координаты=%s\n",
-			cfg.Excel.Columns.Name, cfg.Excel.Columns.Description, cfg.Excel.Columns.Coordinates)
-		fmt.Printf("  🗺️  GeoJSON: %s → %s\n", cfg.Geojson.Input, cfg.Geojson.Output)
+		if cfg.Excel.IsCSV() {
+			fmt.Printf("  📄 CSV файл: %s (кодировка: %s)\n", cfg.Excel.File, cfg.Excel.CSV.Encoding)
+		} else {
+			fmt.Printf("  📊 Excel файл: %s (лист: %s)\n", cfg.Excel.File, cfg.Excel.Sheet)
+		}
+		if cfg.Excel.Columns.HasLatLon() {
+			fmt.Printf("  📍 Столбцы: название=%s, описание=%s, широта=%s, долгота=%s\n",
+				cfg.Excel.Columns.Name, cfg.Excel.Columns.Description, cfg.Excel.Columns.Latitude, cfg.Excel.Columns.Longitude)
+		} else {
+			fmt.Printf("  📍 Столбцы: название=%s, описание=%s, координаты=%s\n",
+				cfg.Excel.Columns.Name, cfg.Excel.Columns.Description, cfg.Excel.Columns.Coordinates)
+		}
+		if order := cfg.Excel.CoordinateOrder; order != config.OrderLatLon {
+			fmt.Printf("  🔀 Порядок координат: %s\n", order)
+		}
+		if cfg.Excel.CRS != crs.WGS84CRS.Code {
+			fmt.Printf("  🌐 Система координат: %s → WGS 84\n", cfg.Excel.CRS)
+		}
+		input := cfg.Geojson.Input
+		if input == "" {
+			input = "(новая коллекция)"
+		}
+		fmt.Printf("  🗺️  GeoJSON: %s → %s\n", input, cfg.Geojson.Output)
+		if cfg.Geojson.RunID != "" {
+			fmt.Printf("  🏷️  Идентификатор импорта: %s\n", cfg.Geojson.RunID)
+		}
+		if zones := cfg.Zones; zones.Enabled() {
+			fmt.Printf("  🧭 Привязка к зонам: свойство %s, точки вне зон: %s\n", zones.Property, zones.Outside)
+		}
+		if upsert := cfg.Geojson.Upsert; upsert.Enabled() {
+			fmt.Printf("  🔁 Обновление объектов по ключу: %s (удаление отсутствующих: %t)\n", upsert.Key, upsert.DeleteMissing)
+		}
+		if writeBack := cfg.WriteBack; writeBack.Enabled() {
+			target := writeBack.Output
+			if writeBack.InPlace {
+				target = cfg.Excel.File + " (исходная книга)"
+			}
+			fmt.Printf("  📝 Результаты строк (%d столбцов) → %s\n", len(writeBack.Columns), target)
+		}
 
 		// Создаем приложение с конфигом
 		// Создаем приложение с конфигом
@@ -76,4 +124,5 @@ func init() {
 	// Добавляем флаг для пути к конфигурационному файлу
 	toGeoJsonCmd.Flags().StringP("config", "c", "", "Путь к конфигурационному YAML файлу (обязателен)")
 	toGeoJsonCmd.MarkFlagRequired("config")
+	toGeoJsonCmd.Flags().String("run-id", "", "Пометить добавленные объекты свойством import_run с этим идентификатором (auto - дата и время запуска)")
 }
@@ -2,12 +2,17 @@ package app
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/rmay1er/jgeo-excel/internal/config"
 	"github.com/rmay1er/jgeo-excel/internal/processors"
 
+	"github.com/rmay1er/jgeo-excel/internal/models"
 	xlsx "github.com/rmay1er/jgeo-excel/internal/readers/excel"
+	gjsreader "github.com/rmay1er/jgeo-excel/internal/readers/geojson"
+	"github.com/rmay1er/jgeo-excel/internal/spatial"
 	"github.com/rmay1er/jgeo-excel/internal/writers"
+	xlsxwriter "github.com/rmay1er/jgeo-excel/internal/writers/excel"
 	gjs "github.com/rmay1er/jgeo-excel/internal/writers/geojson"
 )
 
@@ -16,6 +21,14 @@ type JGeoApp struct {
 	processor *processors.MarksProcessor
 	writer    writers.Writer
 	config    *config.Config
+	// excelReader источник данных, собирает отклонённые строки
+	excelReader *xlsx.ExcelReader
+	// geojsonWriter запоминает, какие объекты добавлены и обновлены
+	geojsonWriter *gjs.GeojsonWriter
+	// checker этап проверки координат
+	checker *processors.CoordinateChecker
+	// zoneTagger этап привязки точек к зонам, nil если привязка выключена
+	zoneTagger *processors.ZoneTagger
 }
 
 // NewApp создает новое приложение с процессором
@@ -29,56 +42,225 @@ func NewJGeoApp(processor *processors.MarksProcessor, writer writers.Writer) *JG
 // NewAppWithConfig создает новое приложение с конфигурацией
 func NewJGeoAppWithConfig(cfg *config.Config) (*JGeoApp, error) {
 	// Создаем Reader для Excel
-	excelReader, err := xlsx.NewExcelReader(
-		cfg.Excel.File,
-		cfg.Excel.Sheet,
-		cfg.Excel.Columns.Name,
-		cfg.Excel.Columns.Description,
-		cfg.Excel.Columns.Coordinates,
-		cfg.Excel.StartRow,
-	)
+	excelReader, err := xlsx.NewExcelReader(cfg.Excel, cfg.Appearance)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось создать Excel reader: %w", err)
 	}
 
-	// Создаем Writer для GeoJSON
-	geojsonWriter, err := gjs.NewGeojsonWriter(cfg.Geojson.Input)
-	if err != nil {
-		excelReader.Close()
-		return nil, fmt.Errorf("не удалось создать GeoJSON writer: %w", err)
+	// Создаем Writer для GeoJSON: дополняем базовый файл или начинаем новую коллекцию
+	geojsonWriter := gjs.NewEmptyGeojsonWriter()
+	if cfg.Geojson.Input != "" {
+		geojsonWriter, err = gjs.NewGeojsonWriter(cfg.Geojson.Input)
+		if err != nil {
+			excelReader.Close()
+			return nil, fmt.Errorf("не удалось создать GeoJSON writer: %w", err)
+		}
+	}
+	geojsonWriter.SetDefaultStyle(cfg.Appearance.Style)
+	geojsonWriter.SetRunID(cfg.Geojson.RunID)
+	if cfg.Geojson.Upsert.Enabled() {
+		geojsonWriter.SetUpsert(cfg.Geojson.Upsert.Key, cfg.Geojson.Upsert.DeleteMissing)
 	}
 
 	// Создаем процессор
 	processor := processors.NewMarksProcessor(excelReader, geojsonWriter)
 
+	// Проверка координат выполняется первой, чтобы следующие этапы получали исправленные точки
+	region, err := coordinateRegion(cfg)
+	if err != nil {
+		processor.Close()
+		return nil, err
+	}
+	checker := processors.NewCoordinateChecker(cfg.Excel.CoordinateOrder, cfg.Excel.Swapped, region)
+	processor.AddStage(checker)
+
+	// Привязка точек к зонам - полигонам базового файла
+	var zoneTagger *processors.ZoneTagger
+	if cfg.Zones.Enabled() {
+		zones, err := loadZones(cfg.Geojson.Input, cfg.Zones.NameFrom)
+		if err != nil {
+			processor.Close()
+			return nil, err
+		}
+		zoneTagger = processors.NewZoneTagger(zones, cfg.Zones)
+		processor.AddStage(zoneTagger)
+	}
+
 	return &JGeoApp{
-		processor: processor,
-		writer:    geojsonWriter,
-		config:    cfg,
+		processor:     processor,
+		writer:        geojsonWriter,
+		config:        cfg,
+		excelReader:   excelReader,
+		geojsonWriter: geojsonWriter,
+		checker:       checker,
+		zoneTagger:    zoneTagger,
 	}, nil
 }
 
+// coordinateRegion возвращает ожидаемую область данных: из excel.region, а в режиме auto без неё -
+// охват базового файла с запасом. nil, если область неизвестна
+func coordinateRegion(cfg *config.Config) (*spatial.BBox, error) {
+	if r := cfg.Excel.Region; len(r) == 4 {
+		return &spatial.BBox{MinLon: r[0], MinLat: r[1], MaxLon: r[2], MaxLat: r[3]}, nil
+	}
+	if cfg.Excel.CoordinateOrder != config.OrderAuto {
+		return nil, nil
+	}
+	if cfg.Geojson.Input == "" {
+		fmt.Println("ℹ️  Область данных не задана (excel.region): перепутанные широта и долгота определяются только по допустимым пределам")
+		return nil, nil
+	}
+
+	features, err := readFeatures(cfg.Geojson.Input)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось определить область данных: %w", err)
+	}
+	geometries := make([]models.Geometry, 0, len(features))
+	for _, feature := range features {
+		geometries = append(geometries, feature.Geometry)
+	}
+	bounds, ok := spatial.Bounds(geometries)
+	if !ok {
+		fmt.Printf("ℹ️  В файле %s нет координат: перепутанные широта и долгота определяются только по допустимым пределам\n", cfg.Geojson.Input)
+		return nil, nil
+	}
+	// Новые точки могут лежать рядом с существующими, поэтому охват расширяется
+	region := bounds.Grow(0.5, 1)
+	fmt.Printf("🧭 Область данных по охвату %s: долгота %.4g…%.4g, широта %.4g…%.4g\n",
+		cfg.Geojson.Input, region.MinLon, region.MaxLon, region.MinLat, region.MaxLat)
+	return &region, nil
+}
+
+// readFeatures читает объекты базового GeoJSON файла
+func readFeatures(path string) ([]models.CordsData, error) {
+	reader, err := gjsreader.NewGeoJSONReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	features, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	return *features, nil
+}
+
+// loadZones читает полигоны зон из базового GeoJSON файла
+func loadZones(path, nameFrom string) ([]processors.Zone, error) {
+	features, err := readFeatures(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать зоны: %w", err)
+	}
+	zones := processors.ZonesFromFeatures(features, nameFrom)
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("в файле %s нет полигонов для привязки к зонам", path)
+	}
+	return zones, nil
+}
+
 // Process выполняет основной процесс обработки координат
 func (a *JGeoApp) ProcessToGeojson() error {
 	if a.config == nil {
 		return fmt.Errorf("конфигурация не установлена")
 	}
 
-	// Выполняем процесс обработки через процессор
-	if err := a.processor.Process(a.config.Appearance.MarkerColor); err != nil {
+	// Выполняем процесс обработки через процессор.
+	// Отчёты об отклонённых строках сохраняются и тогда, когда ни одна строка не прочитана
+	err := a.processor.Process(a.config.Appearance.MarkerColor)
+	for _, row := range a.checker.Rejected {
+		a.excelReader.Reject(row)
+	}
+	for _, row := range a.geojsonWriter.Rejected {
+		a.excelReader.Reject(row)
+	}
+	if err == nil {
+		// Сохраняем результат. Writer формата может пропустить объекты, поэтому отчёты
+		// об отклонённых строках сохраняются после записи результата
+		fmt.Printf("💾 Сохраняю результат в: %s\n", a.config.Geojson.Output)
+		err = a.saveOutput(a.config.Geojson.Output)
+	}
+	if err != nil {
+		if reportErr := a.writeRejectedReports(); reportErr != nil {
+			fmt.Printf("⚠️  %v\n", reportErr)
+		}
+		return err
+	}
+	if err := a.writeRejectedReports(); err != nil {
 		return err
 	}
 
-	// Сохраняем результат
-	fmt.Printf("💾 Сохраняю результат в: %s\n", a.config.Geojson.Output)
-	if err := a.writer.Save(a.config.Geojson.Output); err != nil {
-		return fmt.Errorf("ошибка при сохранении GeoJSON файла: %w", err)
+	if a.zoneTagger != nil && a.config.Zones.Report != "" {
+		fmt.Printf("📊 Сохраняю отчёт о привязке к зонам в: %s\n", a.config.Zones.Report)
+		if err := xlsxwriter.WriteZoneReport(a.config.Zones.Report, zoneReportRows(a.zoneTagger.Matches)); err != nil {
+			return fmt.Errorf("ошибка при сохранении отчёта о зонах: %w", err)
+		}
+	}
+
+	if err := a.writeBack(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeRejectedReports сохраняет отчёт об отклонённых строках и копию книги с выделенными строками
+func (a *JGeoApp) writeRejectedReports() error {
+	cfg := a.config.Rejected
+	if cfg.Report == "" && cfg.Highlight == "" {
+		return nil
 	}
 
+	rows := append([]models.RejectedRow(nil), a.excelReader.Rejected()...)
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Row < rows[j].Row })
+	fmt.Printf("🧾 Отклонено строк: %d\n", len(rows))
+
+	if cfg.Report != "" {
+		width := 0
+		for _, row := range rows {
+			width = max(width, len(row.Values))
+		}
+		fmt.Printf("📊 Сохраняю отчёт об отклонённых строках в: %s\n", cfg.Report)
+		if err := xlsxwriter.WriteRejectedReport(cfg.Report, a.excelReader.Headers(width), rows); err != nil {
+			return fmt.Errorf("ошибка при сохранении отчёта об отклонённых строках: %w", err)
+		}
+	}
+	if cfg.Highlight != "" {
+		fmt.Printf("🖍️  Сохраняю копию книги с выделенными строками в: %s\n", cfg.Highlight)
+		if err := xlsxwriter.WriteHighlightedCopy(a.config.Excel.File, a.config.Excel.Sheet, cfg.Highlight, rows); err != nil {
+			return fmt.Errorf("ошибка при сохранении копии книги: %w", err)
+		}
+	}
 	return nil
 }
 
+// zoneReportRows преобразует результаты привязки в строки отчёта
+func zoneReportRows(matches []processors.ZoneMatch) []xlsxwriter.ZoneReportRow {
+	rows := make([]xlsxwriter.ZoneReportRow, 0, len(matches))
+	for _, match := range matches {
+		row := xlsxwriter.ZoneReportRow{
+			ID:     match.Data.ID,
+			Name:   match.Data.IconCaption,
+			Status: match.Status,
+		}
+		if point, ok := match.Data.Geometry.(*models.PointGeometry); ok && len(point.Coordinates) >= 2 {
+			row.Lon, row.Lat = point.Coordinates[0], point.Coordinates[1]
+		}
+		if match.Zone != nil {
+			row.Zone, row.ZoneID = match.Zone.Name, match.Zone.ID
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// ProcessToExcel читает объекты и сохраняет их в книгу Excel
 func (a *JGeoApp) ProcessToExcel(path string) error {
+	return a.ProcessToFile(path)
+}
+
+// ProcessToFile читает объекты и сохраняет их через writer приложения без цвета по умолчанию
+func (a *JGeoApp) ProcessToFile(path string) error {
 
 	// Выполняем процесс обработки через процессор
 	if err := a.processor.Process(""); err != nil {
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/processors"
+	xlsx "github.com/rmay1er/jgeo-excel/internal/readers/excel"
+	gjs "github.com/rmay1er/jgeo-excel/internal/writers/geojson"
+)
+
+// ChoroplethApp раскрашивает полигоны базового файла по показателям из таблицы Excel
+type ChoroplethApp struct {
+	processor *processors.ChoroplethProcessor
+	writer    *gjs.GeojsonWriter
+	config    *config.Config
+}
+
+// NewChoroplethApp создает приложение картограммы по конфигурации
+func NewChoroplethApp(cfg *config.Config) (*ChoroplethApp, error) {
+	tableReader, err := xlsx.NewExcelTableReader(cfg.Excel)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать Excel reader: %w", err)
+	}
+
+	geojsonWriter, err := gjs.NewGeojsonWriter(cfg.Geojson.Input)
+	if err != nil {
+		tableReader.Close()
+		return nil, fmt.Errorf("не удалось создать GeoJSON writer: %w", err)
+	}
+
+	return &ChoroplethApp{
+		processor: processors.NewChoroplethProcessor(tableReader, geojsonWriter, cfg.Choropleth),
+		writer:    geojsonWriter,
+		config:    cfg,
+	}, nil
+}
+
+// Process присоединяет показатели к полигонам и сохраняет результат
+func (a *ChoroplethApp) Process() error {
+	if err := a.processor.Process(); err != nil {
+		return err
+	}
+
+	fmt.Printf("💾 Сохраняю результат в: %s\n", a.config.Geojson.Output)
+	if err := a.writer.Save(a.config.Geojson.Output); err != nil {
+		return fmt.Errorf("ошибка при сохранении GeoJSON файла: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает процессор
+func (a *ChoroplethApp) Close() error {
+	return a.processor.Close()
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/readers"
+	gjsreader "github.com/rmay1er/jgeo-excel/internal/readers/geojson"
+	gpkgreader "github.com/rmay1er/jgeo-excel/internal/readers/geopackage"
+	gpxreader "github.com/rmay1er/jgeo-excel/internal/readers/gpx"
+	kmlreader "github.com/rmay1er/jgeo-excel/internal/readers/kml"
+	shpreader "github.com/rmay1er/jgeo-excel/internal/readers/shapefile"
+	"github.com/rmay1er/jgeo-excel/internal/writers"
+	xlsxwriter "github.com/rmay1er/jgeo-excel/internal/writers/excel"
+	gjs "github.com/rmay1er/jgeo-excel/internal/writers/geojson"
+	gpkgwriter "github.com/rmay1er/jgeo-excel/internal/writers/geopackage"
+	gpxwriter "github.com/rmay1er/jgeo-excel/internal/writers/gpx"
+	kmlwriter "github.com/rmay1er/jgeo-excel/internal/writers/kml"
+	shpwriter "github.com/rmay1er/jgeo-excel/internal/writers/shapefile"
+)
+
+// Форматы файлов, которые выбираются по расширению
+const (
+	FormatGeoJSON    = "GeoJSON"
+	FormatKML        = "KML"
+	FormatGPX        = "GPX"
+	FormatShapefile  = "Shapefile"
+	FormatGeoPackage = "GeoPackage"
+	FormatExcel      = "Excel"
+)
+
+// FileFormat определяет формат файла по расширению; неизвестные расширения считаются GeoJSON
+func FileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".kml", ".kmz":
+		return FormatKML
+	case ".gpx":
+		return FormatGPX
+	case ".shp", ".zip":
+		return FormatShapefile
+	case ".gpkg":
+		return FormatGeoPackage
+	case ".xlsx":
+		return FormatExcel
+	}
+	return FormatGeoJSON
+}
+
+// NewFileReader создаёт Reader для файла с объектами: GeoJSON, KML, KMZ, GPX, шейп-файла или GeoPackage.
+// Книги Excel читаются по конфигурации (to-geojson), поэтому здесь не поддерживаются
+func NewFileReader(path string) (readers.Reader, error) {
+	switch FileFormat(path) {
+	case FormatKML:
+		return kmlreader.NewKMLReader(path)
+	case FormatGPX:
+		return gpxreader.NewGPXReader(path)
+	case FormatShapefile:
+		return shpreader.NewShapefileReader(path)
+	case FormatGeoPackage:
+		return gpkgreader.NewGeoPackageReader(path)
+	case FormatExcel:
+		return nil, fmt.Errorf("книга Excel %s читается командой to-geojson по конфигурации", path)
+	}
+	return gjsreader.NewGeoJSONReader(path)
+}
+
+// NewFileWriter создаёт Writer для файла по расширению: GeoJSON, KML, KMZ, GPX, шейп-файла, GeoPackage или xlsx
+func NewFileWriter(path string) writers.Writer {
+	switch FileFormat(path) {
+	case FormatKML:
+		return kmlwriter.NewKMLWriter()
+	case FormatGPX:
+		return gpxwriter.NewGPXWriter()
+	case FormatShapefile:
+		return shpwriter.NewShapefileWriter()
+	case FormatGeoPackage:
+		return gpkgwriter.NewGeoPackageWriter()
+	case FormatExcel:
+		return xlsxwriter.NewExcelWriter()
+	}
+	return gjs.NewEmptyGeojsonWriter()
+}
+
+// saveOutput сохраняет результат: GeoJSON - как есть, другие форматы - через Writer формата
+func (a *JGeoApp) saveOutput(path string) error {
+	if FileFormat(path) == FormatGeoJSON {
+		if err := a.writer.Save(path); err != nil {
+			return fmt.Errorf("ошибка при сохранении GeoJSON файла: %w", err)
+		}
+		return nil
+	}
+
+	features := a.geojsonWriter.Features(nil)
+	data := make([]models.CordsData, 0, len(features))
+	for i, feature := range features {
+		item, err := gjsreader.FeatureToCordsData(feature)
+		if err != nil {
+			return fmt.Errorf("объект %d: %w", i+1, err)
+		}
+		data = append(data, item)
+	}
+
+	writer := NewFileWriter(path)
+	defer writer.Close()
+	if gpkg, ok := writer.(*gpkgwriter.GeoPackageWriter); ok && a.config != nil {
+		gpkg.SetLayerProperty(a.config.GeoPackage.LayerProperty)
+	}
+	// Цвет по умолчанию уже записан в объекты GeoJSON writer
+	if err := writer.Write(&data, ""); err != nil {
+		return fmt.Errorf("ошибка при записи %s: %w", FileFormat(path), err)
+	}
+	// Объекты с недопустимой геометрией пропускаются writer формата и попадают в отклонённые строки
+	if rejecting, ok := writer.(writers.RejectingWriter); ok && a.excelReader != nil {
+		for _, row := range rejecting.RejectedRows() {
+			a.excelReader.Reject(row)
+		}
+	}
+	if err := writer.Save(path); err != nil {
+		return fmt.Errorf("ошибка при сохранении файла: %w", err)
+	}
+	return nil
+}
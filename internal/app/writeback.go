@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/processors"
+	"github.com/rmay1er/jgeo-excel/internal/wkt"
+	xlsxwriter "github.com/rmay1er/jgeo-excel/internal/writers/excel"
+	gjs "github.com/rmay1er/jgeo-excel/internal/writers/geojson"
+)
+
+// Статусы строк в книге с результатами (writeback.columns: status)
+const (
+	rowWritten  = "записана"
+	rowRejected = "отклонена"
+	rowSkipped  = "пропущена"
+)
+
+// rowResult итог обработки строки таблицы
+type rowResult struct {
+	status  string
+	message string
+	// data объект, полученный из строки; nil для отклонённой строки
+	data *models.CordsData
+	// write результат записи объекта в GeoJSON
+	write gjs.WriteResult
+}
+
+// writeBack дописывает результаты обработки строк в копию исходной книги или в неё саму
+func (a *JGeoApp) writeBack() error {
+	cfg := a.config.WriteBack
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	results := a.rowResults()
+	columns := make([]xlsxwriter.WriteBackColumn, 0, len(cfg.Columns))
+	for _, col := range cfg.Columns {
+		values := make(map[int]any)
+		for row, result := range results {
+			if value := a.writeBackValue(col, result); value != nil {
+				values[row] = value
+			}
+		}
+		columns = append(columns, xlsxwriter.WriteBackColumn{Header: col.Header, Column: col.Column, Values: values})
+	}
+
+	target := cfg.Output
+	if cfg.InPlace {
+		target = a.config.Excel.File
+	}
+	fmt.Printf("📝 Записываю результаты обработки строк (%d) в книгу: %s\n", len(results), target)
+	excel := a.config.Excel
+	if err := xlsxwriter.WriteBack(excel.File, excel.Sheet, target, excel.HeaderRow, excel.StartRow, a.excelReader.DataColumns(), columns); err != nil {
+		return fmt.Errorf("ошибка при записи результатов в книгу: %w", err)
+	}
+	return nil
+}
+
+// rowResults собирает итог обработки по номерам строк листа: записанные объекты,
+// точки, пропущенные привязкой к зонам, и отклонённые строки
+func (a *JGeoApp) rowResults() map[int]*rowResult {
+	results := make(map[int]*rowResult)
+
+	data := a.processor.Result()
+	written := a.geojsonWriter.Results()
+	for i := range data {
+		result := &rowResult{status: rowWritten, data: &data[i]}
+		if i < len(written) {
+			result.write = written[i]
+		}
+		if check, ok := data[i].Properties[processors.CoordinateCheckProperty]; ok {
+			result.message = fmt.Sprint(check)
+		}
+		for _, row := range data[i].SourceRows() {
+			results[row] = result
+		}
+	}
+
+	if a.zoneTagger != nil {
+		for i, match := range a.zoneTagger.Matches {
+			if match.Status != processors.ZoneStatusSkipped {
+				continue
+			}
+			result := &rowResult{status: rowSkipped, message: "точка вне всех зон", data: &a.zoneTagger.Matches[i].Data}
+			for _, row := range match.Data.SourceRows() {
+				results[row] = result
+			}
+		}
+	}
+
+	for _, rejected := range a.excelReader.Rejected() {
+		if result, ok := results[rejected.Row]; ok && result.status == rowRejected {
+			result.message += "; " + rejected.Message
+			continue
+		}
+		results[rejected.Row] = &rowResult{status: rowRejected, message: rejected.Message}
+	}
+	return results
+}
+
+// writeBackValue возвращает значение столбца с результатом для строки; nil - ячейка остаётся пустой
+func (a *JGeoApp) writeBackValue(col config.WriteBackColumn, result *rowResult) any {
+	switch col.Value {
+	case config.WriteBackStatus:
+		return result.status
+	case config.WriteBackMessage:
+		return emptyToNil(result.message)
+	case config.WriteBackAction:
+		return emptyToNil(result.write.Action)
+	case config.WriteBackID:
+		return emptyToNil(result.write.ID)
+	}
+
+	if result.data == nil {
+		return nil
+	}
+	switch col.Value {
+	case config.WriteBackLatitude, config.WriteBackLongitude:
+		point, ok := result.data.Geometry.(*models.PointGeometry)
+		if !ok || len(point.Coordinates) < 2 {
+			return nil
+		}
+		if col.Value == config.WriteBackLatitude {
+			return point.Coordinates[1]
+		}
+		return point.Coordinates[0]
+	case config.WriteBackWKT:
+		if result.data.Geometry == nil {
+			return nil
+		}
+		text, err := wkt.Marshal(result.data.Geometry)
+		if err != nil {
+			return nil
+		}
+		return text
+	case config.WriteBackZone:
+		return result.data.Properties[a.config.Zones.Property]
+	case config.WriteBackProperty:
+		return result.data.Properties[col.Property]
+	}
+	return nil
+}
+
+// emptyToNil заменяет пустую строку на nil, чтобы ячейка осталась пустой
+func emptyToNil(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,80 @@
+// Package classify разбивает числовые значения на классы для картограмм
+// и подбирает цвета классов.
+//
+// Границы классов (breaks) - внутренние пороги: для n классов их n-1.
+// Значение попадает в класс i, если оно не больше breaks[i] и больше breaks[i-1]
+package classify
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Методы классификации
+const (
+	MethodEqualInterval = "equal_interval"
+	MethodQuantile      = "quantile"
+	MethodManual        = "manual"
+)
+
+// EqualInterval делит диапазон значений на n равных интервалов
+func EqualInterval(values []float64, n int) []float64 {
+	if len(values) == 0 || n < 2 {
+		return nil
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+
+	step := (hi - lo) / float64(n)
+	breaks := make([]float64, n-1)
+	for i := range breaks {
+		breaks[i] = lo + step*float64(i+1)
+	}
+	return breaks
+}
+
+// Quantile подбирает пороги так, чтобы в каждый класс попало примерно одинаковое число значений
+func Quantile(values []float64, n int) []float64 {
+	if len(values) == 0 || n < 2 {
+		return nil
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	breaks := make([]float64, n-1)
+	for i := range breaks {
+		// Позиция квантиля (i+1)/n с линейной интерполяцией между соседними значениями
+		pos := float64(i+1) / float64(n) * float64(len(sorted)-1)
+		lower := int(math.Floor(pos))
+		upper := int(math.Ceil(pos))
+		breaks[i] = sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+	}
+	return breaks
+}
+
+// Breaks вычисляет пороги выбранным методом; для manual возвращаются заданные пороги
+func Breaks(method string, values []float64, n int, manual []float64) ([]float64, error) {
+	switch method {
+	case MethodEqualInterval:
+		return EqualInterval(values, n), nil
+	case MethodQuantile:
+		return Quantile(values, n), nil
+	case MethodManual:
+		if !sort.Float64sAreSorted(manual) {
+			return nil, fmt.Errorf("пороги классов должны идти по возрастанию")
+		}
+		return manual, nil
+	}
+	return nil, fmt.Errorf("неизвестный метод классификации '%s': ожидается %s, %s или %s",
+		method, MethodEqualInterval, MethodQuantile, MethodManual)
+}
+
+// ClassOf возвращает номер класса значения (с 0)
+func ClassOf(value float64, breaks []float64) int {
+	return sort.SearchFloat64s(breaks, value)
+}
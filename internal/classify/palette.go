@@ -0,0 +1,84 @@
+package classify
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// DefaultPalette палитра по умолчанию: от светло-жёлтого к тёмно-красному (ColorBrewer YlOrRd)
+var DefaultPalette = []string{"#FFFFB2", "#FECC5C", "#FD8D3C", "#F03B20", "#BD0026"}
+
+// Palette возвращает n цветов: палитра нужной длины используется как есть,
+// иначе цвета равномерно интерполируются между опорными. Цвета задаются в формате #RRGGBB
+func Palette(colors []string, n int) ([]string, error) {
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("палитра пуста")
+	}
+	if len(colors) == n {
+		return colors, nil
+	}
+
+	stops := make([][3]float64, len(colors))
+	for i, color := range colors {
+		rgb, err := parseHex(color)
+		if err != nil {
+			return nil, err
+		}
+		stops[i] = rgb
+	}
+
+	result := make([]string, n)
+	for i := range result {
+		if len(stops) == 1 || n == 1 {
+			result[i] = formatHex(stops[0])
+			continue
+		}
+		// Положение цвета на палитре от 0 до числа интервалов между опорными цветами
+		pos := float64(i) / float64(n-1) * float64(len(stops)-1)
+		lower := int(pos)
+		if lower >= len(stops)-1 {
+			lower = len(stops) - 2
+		}
+		t := pos - float64(lower)
+		var rgb [3]float64
+		for c := range rgb {
+			rgb[c] = stops[lower][c] + (stops[lower+1][c]-stops[lower][c])*t
+		}
+		result[i] = formatHex(rgb)
+	}
+	return result, nil
+}
+
+// Ramp возвращает n значений, равномерно распределённых от from до to (с точностью до тысячных)
+func Ramp(from, to float64, n int) []float64 {
+	result := make([]float64, n)
+	for i := range result {
+		if n == 1 {
+			result[i] = to
+			continue
+		}
+		value := from + (to-from)*float64(i)/float64(n-1)
+		result[i] = math.Round(value*1000) / 1000
+	}
+	return result
+}
+
+func parseHex(color string) ([3]float64, error) {
+	var rgb [3]float64
+	if len(color) != 7 || color[0] != '#' {
+		return rgb, fmt.Errorf("цвет '%s' должен быть в формате #RRGGBB", color)
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(color[1+i*2:3+i*2], 16, 8)
+		if err != nil {
+			return rgb, fmt.Errorf("цвет '%s' должен быть в формате #RRGGBB", color)
+		}
+		rgb[i] = float64(v)
+	}
+	return rgb, nil
+}
+
+func formatHex(rgb [3]float64) string {
+	return fmt.Sprintf("#%02X%02X%02X", int(rgb[0]+0.5), int(rgb[1]+0.5), int(rgb[2]+0.5))
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/classify"
+	"github.com/spf13/viper"
+)
+
+// ChoroplethConfig настройки картограммы: присоединение строк таблицы к полигонам
+// и раскраска полигонов по числовому показателю
+type ChoroplethConfig struct {
+	// JoinOn свойство полигона, которое сравнивается с ключом строки (excel.columns.id):
+	// iconCaption, id или имя свойства. По умолчанию iconCaption
+	JoinOn string
+	// Value свойство из excel.properties с числовым показателем
+	Value string
+	// Method метод классификации: equal_interval, quantile или manual
+	Method string
+	// Classes количество классов
+	Classes int
+	// Breaks пороги классов для метода manual (по возрастанию)
+	Breaks []float64
+	// Palette цвета заливки классов; если цветов меньше или больше, чем классов, они интерполируются
+	Palette []string
+	// FillOpacity прозрачность заливки всех классов
+	FillOpacity float64
+	// OpacityRange прозрачность заливки от первого класса к последнему (два значения), заменяет FillOpacity
+	OpacityRange []float64
+	// NoDataColor цвет полигонов, для которых нет строки в таблице (опционально)
+	NoDataColor string
+}
+
+// LoadChoroplethConfig загружает конфигурацию команды choropleth
+func LoadChoroplethConfig(path string) (*Config, error) {
+	config, v, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadChoropleth(v, &config.Choropleth); err != nil {
+		return nil, err
+	}
+
+	if err := config.ValidateChoropleth(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// loadChoropleth читает раздел choropleth
+func loadChoropleth(v *viper.Viper, c *ChoroplethConfig) error {
+	c.JoinOn = strings.TrimSpace(v.GetString("choropleth.join_on"))
+	c.Value = strings.TrimSpace(v.GetString("choropleth.value"))
+	c.Method = strings.ToLower(strings.TrimSpace(v.GetString("choropleth.method")))
+	c.Classes = v.GetInt("choropleth.classes")
+	c.Palette = v.GetStringSlice("choropleth.palette")
+	c.NoDataColor = v.GetString("choropleth.no_data_color")
+	c.FillOpacity = -1
+	if v.IsSet("choropleth.fill_opacity") {
+		c.FillOpacity = v.GetFloat64("choropleth.fill_opacity")
+	}
+
+	var err error
+	if c.Breaks, err = loadFloats(v, "choropleth.breaks"); err != nil {
+		return err
+	}
+	if c.OpacityRange, err = loadFloats(v, "choropleth.opacity_range"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// loadFloats читает список чисел
+func loadFloats(v *viper.Viper, key string) ([]float64, error) {
+	var result []float64
+	for i, raw := range v.GetStringSlice(key) {
+		num, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(raw), ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: значение '%s' должно быть числом", key, i, raw)
+		}
+		result = append(result, num)
+	}
+	return result, nil
+}
+
+// ValidateChoropleth проверяет конфигурацию команды choropleth и задаёт значения по умолчанию
+func (c *Config) ValidateChoropleth() error {
+	if c.Excel.File == "" {
+		return fmt.Errorf("путь к Excel файлу не указан (excel.file)")
+	}
+	if !c.Excel.Columns.ID.IsSet() {
+		return fmt.Errorf("столбец ключа для присоединения к полигонам не указан (excel.columns.id)")
+	}
+	if c.Geojson.Input == "" {
+		return fmt.Errorf("файл с полигонами не указан (geojson.input)")
+	}
+	if c.Geojson.Output == "" {
+		return fmt.Errorf("путь к выходному GeoJSON файлу не указан (geojson.output)")
+	}
+	if err := c.applyExcelDefaults(); err != nil {
+		return err
+	}
+	if c.Excel.UsesHeaders() && c.Excel.StartRow <= c.Excel.HeaderRow {
+		return fmt.Errorf("строка начала данных (excel.start_row=%d) должна быть ниже строки заголовков (excel.header_row=%d)", c.Excel.StartRow, c.Excel.HeaderRow)
+	}
+
+	ch := &c.Choropleth
+	if ch.JoinOn == "" {
+		ch.JoinOn = UpsertKeyName
+	}
+
+	found := false
+	for _, prop := range c.Excel.Properties {
+		if prop.Name == ch.Value {
+			if prop.Type != PropertyNumber {
+				return fmt.Errorf("свойство показателя '%s' должно иметь тип number (excel.properties)", ch.Value)
+			}
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("показатель (choropleth.value) должен быть одним из свойств excel.properties с типом number")
+	}
+
+	if ch.Method == "" {
+		ch.Method = classify.MethodEqualInterval
+	}
+	switch ch.Method {
+	case classify.MethodManual:
+		if len(ch.Breaks) == 0 {
+			return fmt.Errorf("для метода manual укажите пороги классов (choropleth.breaks)")
+		}
+		ch.Classes = len(ch.Breaks) + 1
+	case classify.MethodEqualInterval, classify.MethodQuantile:
+		if ch.Classes == 0 {
+			ch.Classes = len(ch.Palette)
+		}
+		if ch.Classes == 0 {
+			ch.Classes = len(classify.DefaultPalette)
+		}
+	default:
+		return fmt.Errorf("неизвестный метод классификации '%s' (choropleth.method): ожидается equal_interval, quantile или manual", ch.Method)
+	}
+	if ch.Classes < 2 {
+		return fmt.Errorf("количество классов (choropleth.classes) должно быть не меньше 2")
+	}
+
+	if len(ch.Palette) == 0 {
+		ch.Palette = append([]string(nil), classify.DefaultPalette...)
+	}
+	for i, color := range ch.Palette {
+		normalized, err := NormalizeColor(color)
+		if err != nil {
+			return fmt.Errorf("choropleth.palette[%d]: %w", i, err)
+		}
+		ch.Palette[i] = normalized
+	}
+	if ch.NoDataColor != "" {
+		normalized, err := NormalizeColor(ch.NoDataColor)
+		if err != nil {
+			return fmt.Errorf("choropleth.no_data_color: %w", err)
+		}
+		ch.NoDataColor = normalized
+	}
+
+	if ch.FillOpacity < 0 {
+		ch.FillOpacity = 0.7
+	}
+	if ch.FillOpacity > 1 {
+		return fmt.Errorf("прозрачность заливки (choropleth.fill_opacity) должна быть от 0 до 1")
+	}
+	if len(ch.OpacityRange) > 0 {
+		if len(ch.OpacityRange) != 2 {
+			return fmt.Errorf("диапазон прозрачности (choropleth.opacity_range) задаётся двумя числами")
+		}
+		for _, o := range ch.OpacityRange {
+			if o < 0 || o > 1 {
+				return fmt.Errorf("значения choropleth.opacity_range должны быть от 0 до 1")
+			}
+		}
+	}
+	return nil
+}
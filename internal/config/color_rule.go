@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var hexColorRe = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// NormalizeColor проверяет цвет в формате HEX и приводит его к виду #RRGGBB
+// (или #RRGGBBAA, если указана прозрачность)
+func NormalizeColor(color string) (string, error) {
+	color = strings.TrimSpace(color)
+	if !hexColorRe.MatchString(color) {
+		return "", fmt.Errorf("цвет '%s' должен быть в формате HEX, например #FF0000", color)
+	}
+
+	hex := strings.ToUpper(strings.TrimPrefix(color, "#"))
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	return "#" + hex, nil
+}
+
+// ColorRule правило выбора цвета по значению столбца.
+// Срабатывает при точном совпадении (Equals), совпадении с регулярным выражением (Regex)
+// или попадании числа в диапазон [Min, Max]. Правила проверяются по порядку
+type ColorRule struct {
+	Equals string
+	Regex  string
+	Min    *float64
+	Max    *float64
+	Color  string
+
+	re *regexp.Regexp
+}
+
+// Matches проверяет, подходит ли значение под правило
+func (r *ColorRule) Matches(value string) bool {
+	value = strings.TrimSpace(value)
+	switch {
+	case r.Equals != "":
+		return strings.EqualFold(value, strings.TrimSpace(r.Equals))
+	case r.re != nil:
+		return r.re.MatchString(value)
+	case r.Min != nil || r.Max != nil:
+		num, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		if err != nil {
+			return false
+		}
+		return (r.Min == nil || num >= *r.Min) && (r.Max == nil || num <= *r.Max)
+	}
+	return false
+}
+
+// validate проверяет правило и компилирует регулярное выражение
+func (r *ColorRule) validate() error {
+	conditions := 0
+	if r.Equals != "" {
+		conditions++
+	}
+	if r.Regex != "" {
+		conditions++
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("неверное регулярное выражение '%s': %w", r.Regex, err)
+		}
+		r.re = re
+	}
+	if r.Min != nil || r.Max != nil {
+		conditions++
+	}
+	if conditions != 1 {
+		return fmt.Errorf("укажите ровно одно условие: equals, regex или min/max")
+	}
+
+	color, err := NormalizeColor(r.Color)
+	if err != nil {
+		return err
+	}
+	r.Color = color
+	return nil
+}
+
+// loadColorRules читает список правил раскраски
+func loadColorRules(v *viper.Viper, key string) ([]ColorRule, error) {
+	var raw []struct {
+		Equals string   `mapstructure:"equals"`
+		Regex  string   `mapstructure:"regex"`
+		Min    *float64 `mapstructure:"min"`
+		Max    *float64 `mapstructure:"max"`
+		Color  string   `mapstructure:"color"`
+	}
+	if err := v.UnmarshalKey(key, &raw); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать %s: %w", key, err)
+	}
+
+	rules := make([]ColorRule, 0, len(raw))
+	for _, item := range raw {
+		rules = append(rules, ColorRule{
+			Equals: item.Equals,
+			Regex:  item.Regex,
+			Min:    item.Min,
+			Max:    item.Max,
+			Color:  item.Color,
+		})
+	}
+	return rules, nil
+}
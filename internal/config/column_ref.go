@@ -0,0 +1,131 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ColumnRef ссылка на столбец Excel: по букве или номеру с 1 (Column) или по заголовку (Header).
+// В YAML задаётся строкой с буквой ("C"), номером (3) или объектом ({header: "Координаты"})
+type ColumnRef struct {
+	Column string
+	Header string
+}
+
+// IsSet сообщает, указан ли столбец
+func (c ColumnRef) IsSet() bool {
+	return c.Column != "" || c.Header != ""
+}
+
+// String возвращает описание столбца для вывода пользователю
+func (c ColumnRef) String() string {
+	if c.Header != "" {
+		return fmt.Sprintf("«%s»", c.Header)
+	}
+	return c.Column
+}
+
+// loadColumnRef читает ссылку на столбец по ключу конфигурации
+func loadColumnRef(v *viper.Viper, key string) (ColumnRef, error) {
+	if !v.IsSet(key+".column") && !v.IsSet(key+".header") {
+		return ColumnRef{Column: v.GetString(key)}, nil
+	}
+
+	ref := ColumnRef{
+		Column: v.GetString(key + ".column"),
+		Header: v.GetString(key + ".header"),
+	}
+	if ref.Column != "" && ref.Header != "" {
+		return ColumnRef{}, fmt.Errorf("для столбца %s укажите либо column, либо header", key)
+	}
+	return ref, nil
+}
+
+// Типы значений свойств
+const (
+	PropertyString = "string"
+	PropertyNumber = "number"
+	PropertyBool   = "bool"
+	PropertyJSON   = "json"
+)
+
+// PropertyMapping описывает свойство объекта GeoJSON, значение которого берётся из столбца
+type PropertyMapping struct {
+	// Name ключ свойства; если не указан, используется заголовок столбца
+	Name   string
+	Column ColumnRef
+	// Type тип значения: string (по умолчанию), number, bool или json
+	Type string
+}
+
+// ConvertValue приводит текст ячейки к типу свойства
+func (p PropertyMapping) ConvertValue(value string) (any, error) {
+	switch p.Type {
+	case PropertyNumber:
+		num, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("значение '%s' не является числом", value)
+		}
+		return num, nil
+	case PropertyBool:
+		b, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			return nil, fmt.Errorf("значение '%s' не является логическим", value)
+		}
+		return b, nil
+	case PropertyJSON:
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("значение '%s' не является JSON: %w", value, err)
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// loadPropertyMappings читает список свойств вида
+// [{name: "address", column: "E"}, {header: "Телефон", type: "number"}]
+func loadPropertyMappings(v *viper.Viper, key string) ([]PropertyMapping, error) {
+	var raw []struct {
+		Name   string `mapstructure:"name"`
+		Column string `mapstructure:"column"`
+		Header string `mapstructure:"header"`
+		Type   string `mapstructure:"type"`
+	}
+	if err := v.UnmarshalKey(key, &raw); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать %s: %w", key, err)
+	}
+
+	mappings := make([]PropertyMapping, 0, len(raw))
+	for i, item := range raw {
+		ref := ColumnRef{Column: item.Column, Header: item.Header}
+		switch {
+		case !ref.IsSet():
+			return nil, fmt.Errorf("%s[%d]: не указан столбец (column или header)", key, i)
+		case ref.Column != "" && ref.Header != "":
+			return nil, fmt.Errorf("%s[%d]: укажите либо column, либо header", key, i)
+		}
+
+		name := item.Name
+		if name == "" {
+			name = item.Header
+		}
+		if name == "" {
+			return nil, fmt.Errorf("%s[%d]: не указано имя свойства (name)", key, i)
+		}
+		typ := strings.ToLower(item.Type)
+		switch typ {
+		case "":
+			typ = PropertyString
+		case PropertyString, PropertyNumber, PropertyBool, PropertyJSON:
+		default:
+			return nil, fmt.Errorf("%s[%d]: неизвестный тип '%s': ожидается string, number, bool или json", key, i, item.Type)
+		}
+		mappings = append(mappings, PropertyMapping{Name: name, Column: ref, Type: typ})
+	}
+	return mappings, nil
+}
@@ -2,15 +2,128 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/rmay1er/jgeo-excel/internal/crs"
+	"github.com/rmay1er/jgeo-excel/internal/models"
 	"github.com/spf13/viper"
 )
 
 // ColumnMapping описывает маппинг столбцов Excel
 type ColumnMapping struct {
-	Name        string
-	Description string
-	Coordinates string
+	Name        ColumnRef
+	Description ColumnRef
+	Coordinates ColumnRef
+	// Latitude, Longitude и Altitude задают координаты в отдельных столбцах
+	// (альтернатива столбцу Coordinates, высота необязательна)
+	Latitude  ColumnRef
+	Longitude ColumnRef
+	Altitude  ColumnRef
+	// WKT столбец с геометрией в формате Well-Known Text (альтернатива координатам)
+	WKT ColumnRef
+	// ID столбец с идентификатором объекта (id объекта GeoJSON)
+	ID ColumnRef
+}
+
+// HasLatLon сообщает, заданы ли координаты отдельными столбцами широты и долготы
+func (m ColumnMapping) HasLatLon() bool {
+	return m.Latitude.IsSet() || m.Longitude.IsSet()
+}
+
+// UsesHeaders сообщает, задан ли хотя бы один столбец по заголовку
+func (m ColumnMapping) UsesHeaders() bool {
+	for _, ref := range []ColumnRef{m.Name, m.Description, m.Coordinates, m.Latitude, m.Longitude, m.Altitude, m.WKT, m.ID} {
+		if ref.Header != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesHeaders сообщает, нужна ли строка заголовков для чтения листа
+func (c ExcelConfig) UsesHeaders() bool {
+	if c.Columns.UsesHeaders() || c.AllColumns {
+		return true
+	}
+	if c.Geometry.Group.Header != "" || c.Geometry.Sequence.Header != "" || c.Geometry.Ring.Header != "" {
+		return true
+	}
+	for _, prop := range c.Properties {
+		if prop.Column.Header != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCoordinates проверяет, что геометрия задана ровно одним способом:
+// столбцом координат, парой столбцов широты и долготы или столбцом WKT
+func (m ColumnMapping) ValidateCoordinates() error {
+	sources := 0
+	for _, set := range []bool{m.Coordinates.IsSet(), m.HasLatLon(), m.WKT.IsSet()} {
+		if set {
+			sources++
+		}
+	}
+
+	switch {
+	case sources > 1:
+		return fmt.Errorf("укажите только один источник координат: excel.columns.coordinates, excel.columns.latitude/longitude или excel.columns.wkt")
+	case sources == 0:
+		return fmt.Errorf("столбец для координат не указан (excel.columns.coordinates, excel.columns.latitude/longitude или excel.columns.wkt)")
+	case m.HasLatLon() && !m.Latitude.IsSet():
+		return fmt.Errorf("столбец для широты не указан (excel.columns.latitude)")
+	case m.HasLatLon() && !m.Longitude.IsSet():
+		return fmt.Errorf("столбец для долготы не указан (excel.columns.longitude)")
+	case m.Altitude.IsSet() && !m.HasLatLon():
+		return fmt.Errorf("столбец высоты (excel.columns.altitude) используется только вместе со столбцами широты и долготы")
+	}
+	return nil
+}
+
+// Типы геометрии, собираемой из строк Excel
+const (
+	GeometryPoint      = "point"
+	GeometryLineString = "linestring"
+	GeometryPolygon    = "polygon"
+)
+
+// GeometryConfig описывает сборку линий и полигонов из последовательных строк:
+// строки с одинаковым ключом группы (Group) упорядочиваются по столбцу Sequence
+// и превращаются в линию или полигон. Ring задаёт номер контура полигона (0 - внешний, 1.. - дырки)
+type GeometryConfig struct {
+	Type     string
+	Group    ColumnRef
+	Sequence ColumnRef
+	Ring     ColumnRef
+}
+
+// Validate проверяет режим сборки геометрии, по умолчанию каждая строка - отдельная точка
+func (g *GeometryConfig) Validate() error {
+	g.Type = strings.ToLower(g.Type)
+	switch g.Type {
+	case "":
+		g.Type = GeometryPoint
+	case GeometryPoint, GeometryLineString, GeometryPolygon:
+	default:
+		return fmt.Errorf("неизвестный тип геометрии '%s' (excel.geometry.type): ожидается point, linestring или polygon", g.Type)
+	}
+
+	if g.Type == GeometryPoint {
+		if g.Group.IsSet() || g.Sequence.IsSet() || g.Ring.IsSet() {
+			return fmt.Errorf("столбцы excel.geometry.group/sequence/ring используются только для типов linestring и polygon")
+		}
+		return nil
+	}
+	if !g.Group.IsSet() {
+		return fmt.Errorf("для типа геометрии %s нужно указать столбец группы (excel.geometry.group)", g.Type)
+	}
+	if g.Ring.IsSet() && g.Type != GeometryPolygon {
+		return fmt.Errorf("столбец контура (excel.geometry.ring) используется только для полигонов")
+	}
+	return nil
 }
 
 // ExcelConfig конфигурация для работы с Excel файлом
@@ -19,17 +132,161 @@ type ExcelConfig struct {
 	Sheet    string
 	Columns  ColumnMapping
 	StartRow int
+	// HeaderRow номер строки с заголовками, используется для столбцов, заданных по заголовку
+	HeaderRow int
+	// DecimalSeparator разделитель дробной части в координатах: auto, dot или comma
+	DecimalSeparator string
+	// Properties дополнительные столбцы, которые попадают в свойства объектов GeoJSON
+	Properties []PropertyMapping
+	// AllColumns переносит в свойства все столбцы строки (ключ - заголовок столбца)
+	AllColumns bool
+	// Geometry режим сборки линий и полигонов из строк
+	Geometry GeometryConfig
+	// CRS система координат таблицы (код EPSG, например EPSG:28407), по умолчанию WGS 84 (EPSG:4326).
+	// Координаты других систем переводятся в WGS 84 при чтении
+	CRS string
+	// CoordinateOrder порядок координат в столбце coordinates: latlon (по умолчанию), lonlat или auto.
+	// В режиме auto строки с перепутанными широтой и долготой ищутся по области Region
+	// или по охвату базового GeoJSON файла
+	CoordinateOrder string
+	// Region ожидаемая область данных [мин. долгота, мин. широта, макс. долгота, макс. широта] (опционально)
+	Region []float64
+	// Swapped действие со строками, у которых перепутаны широта и долгота (режим auto): fix, flag или skip
+	Swapped string
+	// CSV параметры чтения, если excel.file - текстовая таблица .csv, .tsv или .txt
+	CSV CSVConfig
 }
 
+// Порядок координат в ячейке (excel.coordinate_order)
+const (
+	OrderLatLon = "latlon"
+	OrderLonLat = "lonlat"
+	OrderAuto   = "auto"
+)
+
+// Действия со строками, у которых перепутаны широта и долгота (excel.swapped)
+const (
+	SwappedFix  = "fix"
+	SwappedFlag = "flag"
+	SwappedSkip = "skip"
+)
+
 // GeojsonConfig конфигурация для работы с GeoJSON файлом
 type GeojsonConfig struct {
+	// Input базовый файл, в который добавляются объекты; если не указан, создаётся новая коллекция
 	Input  string
 	Output string
+	// Upsert обновление объектов базового файла по ключу вместо добавления дубликатов
+	Upsert UpsertConfig
+	// RunID идентификатор запуска, которым помечаются добавленные объекты; пусто - объекты не помечаются,
+	// RunIDAuto - дата и время запуска
+	RunID string
+}
+
+// RunIDAuto значение geojson.run_id и флага --run-id, при котором идентификатором запуска
+// становятся дата и время запуска
+const RunIDAuto = "auto"
+
+// ResolveRunID возвращает идентификатор запуска: для RunIDAuto - дату и время запуска, иначе значение как есть
+func ResolveRunID(value string) string {
+	if strings.EqualFold(value, RunIDAuto) {
+		return time.Now().Format("20060102-150405")
+	}
+	return value
+}
+
+// Специальные значения geojson.upsert.key: идентификатор объекта и его название
+const (
+	UpsertKeyID   = "id"
+	UpsertKeyName = "iconCaption"
+)
+
+// UpsertConfig настройки обновления объектов по ключу
+type UpsertConfig struct {
+	// Key ключ объекта: id (идентификатор из excel.columns.id), iconCaption (название)
+	// или имя свойства из excel.properties
+	Key string
+	// DeleteMissing удаляет объекты базового файла, ключа которых больше нет в таблице
+	DeleteMissing bool
 }
 
+// Enabled сообщает, включён ли режим обновления по ключу
+func (u UpsertConfig) Enabled() bool {
+	return u.Key != ""
+}
+
+// Действия с точками, которые не попали ни в одну зону (zones.outside)
+const (
+	ZonesOutsideKeep = "keep"
+	ZonesOutsideTag  = "tag"
+	ZonesOutsideSkip = "skip"
+)
+
+// Специальные значения zones.name_from: название полигона и его идентификатор
+const (
+	ZoneNameFromCaption = "iconCaption"
+	ZoneNameFromID      = "id"
+)
+
+// ZonesConfig привязка точек к зонам - полигонам базового GeoJSON файла
+type ZonesConfig struct {
+	// Property свойство точки, в которое записывается название зоны; пустое значение отключает привязку
+	Property string
+	// IDProperty свойство точки для идентификатора зоны (опционально)
+	IDProperty string
+	// NameFrom свойство полигона с названием зоны, по умолчанию iconCaption
+	NameFrom string
+	// Outside действие с точками вне всех зон: keep, tag или skip
+	Outside string
+	// OutsideValue значение Property для точек вне зон при Outside = tag
+	OutsideValue string
+	// Report путь к книге Excel с отчётом о привязке (опционально)
+	Report string
+}
+
+// Enabled сообщает, включена ли привязка точек к зонам
+func (z ZonesConfig) Enabled() bool {
+	return z.Property != ""
+}
+
+// RejectedConfig отчёты о строках таблицы, которые не попали в результат
+type RejectedConfig struct {
+	// Report путь к отчёту об отклонённых строках: книга .xlsx или файл .csv (опционально)
+	Report string
+	// Highlight путь к копии исходной книги, в которой отклонённые строки выделены цветом,
+	// а к ячейке с ошибкой добавлен комментарий (опционально)
+	Highlight string
+}
+
+// NoColor значение appearance.marker_color, отключающее цвет маркеров по умолчанию
+const NoColor = "none"
+
 // AppearanceConfig конфигурация внешнего вида маркеров
 type AppearanceConfig struct {
+	// MarkerColor цвет маркеров по умолчанию; пустая строка - не задавать цвет
 	MarkerColor string
+	// ColorColumn столбец, в котором цвет маркера записан в формате HEX
+	ColorColumn ColumnRef
+	// ColorBy столбец, значение которого сопоставляется с правилами ColorRules
+	ColorBy    ColumnRef
+	ColorRules []ColorRule
+	// Style статическое оформление объектов (marker-symbol, stroke, fill и т.д.)
+	Style models.Style
+	// StyleColumns столбцы, из которых оформление берётся для каждой строки (ключ - параметр оформления)
+	StyleColumns map[string]ColumnRef
+}
+
+// UsesHeaders сообщает, задан ли столбец внешнего вида по заголовку
+func (a AppearanceConfig) UsesHeaders() bool {
+	if a.ColorColumn.Header != "" || a.ColorBy.Header != "" {
+		return true
+	}
+	for _, ref := range a.StyleColumns {
+		if ref.Header != "" {
+			return true
+		}
+	}
+	return false
 }
 
 // Config основная структура конфигурации
@@ -37,10 +294,34 @@ type Config struct {
 	Excel      ExcelConfig
 	Geojson    GeojsonConfig
 	Appearance AppearanceConfig
+	Zones      ZonesConfig
+	// Rejected отчёты об отклонённых строках
+	Rejected RejectedConfig
+	// WriteBack запись результатов обработки в исходную книгу
+	WriteBack WriteBackConfig
+	// Choropleth настройки команды choropleth
+	Choropleth ChoroplethConfig
+	// GeoPackage настройки записи результата в GeoPackage
+	GeoPackage GeoPackageConfig
 }
 
 // LoadConfig загружает конфигурацию из файла используя Viper
 func LoadConfig(path string) (*Config, error) {
+	config, _, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Валидация конфигурации
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// readConfig читает общие разделы конфигурации без проверки обязательных параметров
+func readConfig(path string) (*Config, *viper.Viper, error) {
 	v := viper.New()
 
 	// Устанавливаем путь и имя файла конфигурации
@@ -48,7 +329,7 @@ func LoadConfig(path string) (*Config, error) {
 
 	// Читаем файл конфигурации
 	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("не удалось прочитать файл конфигурации: %w", err)
+		return nil, nil, fmt.Errorf("не удалось прочитать файл конфигурации: %w", err)
 	}
 
 	// Парсим конфигурацию в структуру
@@ -57,24 +338,96 @@ func LoadConfig(path string) (*Config, error) {
 	// Excel конфигурация
 	config.Excel.File = v.GetString("excel.file")
 	config.Excel.Sheet = v.GetString("excel.sheet")
-	config.Excel.Columns.Name = v.GetString("excel.columns.name")
-	config.Excel.Columns.Description = v.GetString("excel.columns.description")
-	config.Excel.Columns.Coordinates = v.GetString("excel.columns.coordinates")
+	columns := map[string]*ColumnRef{
+		"name":        &config.Excel.Columns.Name,
+		"description": &config.Excel.Columns.Description,
+		"coordinates": &config.Excel.Columns.Coordinates,
+		"latitude":    &config.Excel.Columns.Latitude,
+		"longitude":   &config.Excel.Columns.Longitude,
+		"altitude":    &config.Excel.Columns.Altitude,
+		"wkt":         &config.Excel.Columns.WKT,
+		"id":          &config.Excel.Columns.ID,
+	}
+	for key, ref := range columns {
+		col, err := loadColumnRef(v, "excel.columns."+key)
+		if err != nil {
+			return nil, nil, err
+		}
+		*ref = col
+	}
 	config.Excel.StartRow = v.GetInt("excel.start_row")
+	config.Excel.HeaderRow = v.GetInt("excel.header_row")
+	config.Excel.DecimalSeparator = v.GetString("excel.decimal_separator")
+	config.Excel.AllColumns = v.GetBool("excel.all_columns")
+	config.Excel.CRS = strings.TrimSpace(v.GetString("excel.crs"))
+	config.Excel.CoordinateOrder = strings.ToLower(strings.TrimSpace(v.GetString("excel.coordinate_order")))
+	config.Excel.Swapped = strings.ToLower(strings.TrimSpace(v.GetString("excel.swapped")))
+	config.Excel.CSV = loadCSV(v)
+	properties, err := loadPropertyMappings(v, "excel.properties")
+	if err != nil {
+		return nil, nil, err
+	}
+	config.Excel.Properties = properties
+	if config.Excel.Region, err = loadFloats(v, "excel.region"); err != nil {
+		return nil, nil, err
+	}
+	config.Excel.Geometry.Type = v.GetString("excel.geometry.type")
+	geometryColumns := map[string]*ColumnRef{
+		"group":    &config.Excel.Geometry.Group,
+		"sequence": &config.Excel.Geometry.Sequence,
+		"ring":     &config.Excel.Geometry.Ring,
+	}
+	for key, ref := range geometryColumns {
+		col, err := loadColumnRef(v, "excel.geometry."+key)
+		if err != nil {
+			return nil, nil, err
+		}
+		*ref = col
+	}
 
 	// GeoJSON конфигурация
 	config.Geojson.Input = v.GetString("geojson.input")
 	config.Geojson.Output = v.GetString("geojson.output")
+	config.Geojson.RunID = strings.TrimSpace(v.GetString("geojson.run_id"))
+	config.Geojson.Upsert.Key = strings.TrimSpace(v.GetString("geojson.upsert.key"))
+	config.Geojson.Upsert.DeleteMissing = v.GetBool("geojson.upsert.delete_missing")
+
+	// Привязка к зонам
+	config.Zones.Property = strings.TrimSpace(v.GetString("zones.property"))
+	config.Zones.IDProperty = strings.TrimSpace(v.GetString("zones.id_property"))
+	config.Zones.NameFrom = strings.TrimSpace(v.GetString("zones.name_from"))
+	config.Zones.Outside = strings.ToLower(strings.TrimSpace(v.GetString("zones.outside")))
+	config.Zones.OutsideValue = v.GetString("zones.outside_value")
+	config.Zones.Report = v.GetString("zones.report")
+
+	// Отчёты об отклонённых строках
+	config.Rejected.Report = strings.TrimSpace(v.GetString("rejected.report"))
+	config.Rejected.Highlight = strings.TrimSpace(v.GetString("rejected.highlight"))
+
+	// Запись результатов в исходную книгу
+	if config.WriteBack, err = loadWriteBack(v); err != nil {
+		return nil, nil, err
+	}
+
+	// Слои GeoPackage
+	config.GeoPackage = loadGeoPackage(v)
 
 	// Appearance конфигурация
 	config.Appearance.MarkerColor = v.GetString("appearance.marker_color")
-
-	// Валидация конфигурации
-	if err := config.Validate(); err != nil {
-		return nil, err
+	if config.Appearance.ColorColumn, err = loadColumnRef(v, "appearance.color_column"); err != nil {
+		return nil, nil, err
+	}
+	if config.Appearance.ColorBy, err = loadColumnRef(v, "appearance.color_by"); err != nil {
+		return nil, nil, err
+	}
+	if config.Appearance.ColorRules, err = loadColorRules(v, "appearance.color_rules"); err != nil {
+		return nil, nil, err
+	}
+	if err := loadStyle(v, &config.Appearance); err != nil {
+		return nil, nil, err
 	}
 
-	return config, nil
+	return config, v, nil
 }
 
 // Validate проверяет валидность конфигурации
@@ -85,33 +438,249 @@ func (c *Config) Validate() error {
 	// if c.Excel.Columns.Name == "" {
 	// 	return fmt.Errorf("столбец для названия не указан (excel.columns.name)")
 	// }
-	if c.Excel.Columns.Description == "" {
+	if !c.Excel.Columns.Description.IsSet() {
 		return fmt.Errorf("столбец для описания не указан (excel.columns.description)")
 	}
-	if c.Excel.Columns.Coordinates == "" {
-		return fmt.Errorf("столбец для координат не указан (excel.columns.coordinates)")
-	}
-	if c.Geojson.Input == "" {
-		return fmt.Errorf("путь к входному GeoJSON файлу не указан (geojson.input)")
+	if err := c.Excel.Columns.ValidateCoordinates(); err != nil {
+		return err
 	}
 	if c.Geojson.Output == "" {
 		return fmt.Errorf("путь к выходному GeoJSON файлу не указан (geojson.output)")
 	}
 
+	if err := c.applyExcelDefaults(); err != nil {
+		return err
+	}
+	if (c.Excel.UsesHeaders() || c.Appearance.UsesHeaders()) && c.Excel.StartRow <= c.Excel.HeaderRow {
+		return fmt.Errorf("строка начала данных (excel.start_row=%d) должна быть ниже строки заголовков (excel.header_row=%d)", c.Excel.StartRow, c.Excel.HeaderRow)
+	}
+
+	if err := c.Excel.Geometry.Validate(); err != nil {
+		return err
+	}
+	if c.Excel.Columns.WKT.IsSet() && c.Excel.Geometry.Type != GeometryPoint {
+		return fmt.Errorf("столбец WKT (excel.columns.wkt) уже содержит готовую геометрию и не используется со сборкой excel.geometry")
+	}
+
+	// Если система координат не указана, координаты считаются градусами WGS 84
+	if c.Excel.CRS == "" {
+		c.Excel.CRS = crs.WGS84CRS.Code
+	}
+	system, err := crs.Parse(c.Excel.CRS)
+	if err != nil {
+		return fmt.Errorf("excel.crs: %w", err)
+	}
+	c.Excel.CRS = system.Code
+
+	if err := c.validateCoordinateOrder(system); err != nil {
+		return err
+	}
+
+	if err := c.validateUpsert(); err != nil {
+		return err
+	}
+
+	if err := c.validateZones(); err != nil {
+		return err
+	}
+
+	if err := c.validateRejected(); err != nil {
+		return err
+	}
+
+	if err := c.validateWriteBack(); err != nil {
+		return err
+	}
+
+	if err := c.validateGeoPackage(); err != nil {
+		return err
+	}
+
+	// Объекты помечаются идентификатором запуска, только если он задан
+	c.Geojson.RunID = ResolveRunID(c.Geojson.RunID)
+
+	// Если цвет маркера не указан, используем красный по умолчанию,
+	// значение none отключает цвет по умолчанию
+	switch strings.ToLower(c.Appearance.MarkerColor) {
+	case "":
+		c.Appearance.MarkerColor = "#FF0000"
+	case NoColor:
+		c.Appearance.MarkerColor = ""
+	default:
+		color, err := NormalizeColor(c.Appearance.MarkerColor)
+		if err != nil {
+			return fmt.Errorf("неверный цвет маркера (appearance.marker_color): %w", err)
+		}
+		c.Appearance.MarkerColor = color
+	}
+
+	if len(c.Appearance.ColorRules) > 0 && !c.Appearance.ColorBy.IsSet() {
+		return fmt.Errorf("для правил раскраски (appearance.color_rules) нужно указать столбец appearance.color_by")
+	}
+	for i := range c.Appearance.ColorRules {
+		if err := c.Appearance.ColorRules[i].validate(); err != nil {
+			return fmt.Errorf("appearance.color_rules[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateCoordinateOrder проверяет порядок координат и область данных, задаёт значения по умолчанию
+func (c *Config) validateCoordinateOrder(system *crs.CRS) error {
+	e := &c.Excel
+	switch e.CoordinateOrder {
+	case "":
+		e.CoordinateOrder = OrderLatLon
+	case OrderLatLon, OrderAuto:
+	case OrderLonLat:
+		if !e.Columns.Coordinates.IsSet() {
+			return fmt.Errorf("порядок lonlat (excel.coordinate_order) относится к столбцу excel.columns.coordinates")
+		}
+	default:
+		return fmt.Errorf("неизвестный порядок координат '%s' (excel.coordinate_order): ожидается latlon, lonlat или auto", e.CoordinateOrder)
+	}
+	if system.IsProjected() && e.CoordinateOrder != OrderLatLon {
+		return fmt.Errorf("для прямоугольной системы координат %s порядок всегда X Y: excel.coordinate_order не используется", system.Code)
+	}
+
+	switch e.Swapped {
+	case "":
+		e.Swapped = SwappedFix
+	case SwappedFix, SwappedFlag, SwappedSkip:
+		if e.CoordinateOrder != OrderAuto {
+			return fmt.Errorf("excel.swapped используется только с excel.coordinate_order: auto")
+		}
+	default:
+		return fmt.Errorf("неизвестное действие '%s' (excel.swapped): ожидается fix, flag или skip", e.Swapped)
+	}
+
+	if len(e.Region) == 0 {
+		return nil
+	}
+	if len(e.Region) != 4 {
+		return fmt.Errorf("область excel.region задаётся четырьмя числами: [мин. долгота, мин. широта, макс. долгота, макс. широта]")
+	}
+	minLon, minLat, maxLon, maxLat := e.Region[0], e.Region[1], e.Region[2], e.Region[3]
+	if minLon >= maxLon || minLat >= maxLat {
+		return fmt.Errorf("в excel.region минимальные значения должны быть меньше максимальных")
+	}
+	if minLon < -180 || maxLon > 180 || minLat < -90 || maxLat > 90 {
+		return fmt.Errorf("excel.region выходит за пределы допустимых координат (долгота ±180, широта ±90)")
+	}
+	return nil
+}
+
+// validateUpsert проверяет, что ключ обновления читается из таблицы
+func (c *Config) validateUpsert() error {
+	upsert := c.Geojson.Upsert
+	if !upsert.Enabled() {
+		if upsert.DeleteMissing {
+			return fmt.Errorf("geojson.upsert.delete_missing требует ключ объекта (geojson.upsert.key)")
+		}
+		return nil
+	}
+
+	switch upsert.Key {
+	case UpsertKeyID:
+		if !c.Excel.Columns.ID.IsSet() {
+			return fmt.Errorf("ключ обновления id требует столбец идентификатора (excel.columns.id)")
+		}
+	case UpsertKeyName:
+		if !c.Excel.Columns.Name.IsSet() {
+			return fmt.Errorf("ключ обновления iconCaption требует столбец названия (excel.columns.name)")
+		}
+	default:
+		if c.Excel.AllColumns {
+			return nil
+		}
+		for _, prop := range c.Excel.Properties {
+			if prop.Name == upsert.Key {
+				return nil
+			}
+		}
+		return fmt.Errorf("ключ обновления '%s' (geojson.upsert.key) не найден среди свойств excel.properties", upsert.Key)
+	}
+	return nil
+}
+
+// validateZones проверяет настройки привязки к зонам и задаёт значения по умолчанию
+func (c *Config) validateZones() error {
+	z := &c.Zones
+	if !z.Enabled() {
+		if z.Report != "" || z.IDProperty != "" {
+			return fmt.Errorf("для привязки к зонам укажите свойство точки (zones.property)")
+		}
+		return nil
+	}
+	if c.Geojson.Input == "" {
+		return fmt.Errorf("зоны берутся из базового файла: укажите geojson.input для привязки к зонам (zones)")
+	}
+
+	if z.NameFrom == "" {
+		z.NameFrom = ZoneNameFromCaption
+	}
+	switch z.Outside {
+	case "":
+		z.Outside = ZonesOutsideKeep
+	case ZonesOutsideKeep, ZonesOutsideSkip:
+	case ZonesOutsideTag:
+		if z.OutsideValue == "" {
+			z.OutsideValue = "вне зон"
+		}
+	default:
+		return fmt.Errorf("неизвестное действие '%s' (zones.outside): ожидается keep, tag или skip", z.Outside)
+	}
+	return nil
+}
+
+// validateRejected проверяет пути отчётов об отклонённых строках
+func (c *Config) validateRejected() error {
+	if report := c.Rejected.Report; report != "" {
+		switch strings.ToLower(filepath.Ext(report)) {
+		case ".xlsx", ".csv":
+		default:
+			return fmt.Errorf("отчёт об отклонённых строках (rejected.report) должен быть файлом .xlsx или .csv")
+		}
+	}
+	if highlight := c.Rejected.Highlight; highlight != "" {
+		if !strings.EqualFold(filepath.Ext(highlight), ".xlsx") {
+			return fmt.Errorf("копия книги с выделенными строками (rejected.highlight) должна быть файлом .xlsx")
+		}
+		if filepath.Clean(highlight) == filepath.Clean(c.Excel.File) {
+			return fmt.Errorf("rejected.highlight не может совпадать с исходной книгой excel.file")
+		}
+		if c.Excel.IsCSV() {
+			return fmt.Errorf("строки выделяются только в копии книги .xlsx, для CSV используйте rejected.report")
+		}
+	}
+	return nil
+}
+
+// applyExcelDefaults задаёт значения по умолчанию для параметров чтения листа
+func (c *Config) applyExcelDefaults() error {
 	// Если лист не указан, используем Sheet1 по умолчанию
 	if c.Excel.Sheet == "" {
 		c.Excel.Sheet = "Sheet1"
 	}
 
-	// Если startRow не указан, используем 2 (т.к. 1я строка - заголовки)
-	if c.Excel.StartRow == 0 {
-		c.Excel.StartRow = 2
+	// Если строка заголовков не указана, используем первую
+	if c.Excel.HeaderRow == 0 {
+		c.Excel.HeaderRow = 1
 	}
 
-	// Если цвет маркера не указан, используем красный по умолчанию
-	if c.Appearance.MarkerColor == "" {
-		c.Appearance.MarkerColor = "#FF0000"
+	// Если startRow не указан, начинаем со строки после заголовков
+	if c.Excel.StartRow == 0 {
+		c.Excel.StartRow = c.Excel.HeaderRow + 1
 	}
 
-	return nil
+	// Если разделитель дробной части не указан, определяем его автоматически
+	switch c.Excel.DecimalSeparator {
+	case "":
+		c.Excel.DecimalSeparator = "auto"
+	case "auto", "dot", "comma":
+	default:
+		return fmt.Errorf("неизвестный разделитель дробной части '%s' (excel.decimal_separator): ожидается auto, dot или comma", c.Excel.DecimalSeparator)
+	}
+	return c.Excel.validateCSV()
 }
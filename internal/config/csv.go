@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+)
+
+// Кодировки текстовых таблиц (excel.csv.encoding)
+const (
+	EncodingAuto   = "auto"
+	EncodingUTF8   = "utf-8"
+	EncodingCP1251 = "cp1251"
+	EncodingKOI8R  = "koi8-r"
+)
+
+// QuoteNone отключает кавычки: значения читаются как есть до разделителя
+const QuoteNone = "none"
+
+// CSVConfig параметры чтения текстовой таблицы (выгрузки 1С и других систем)
+type CSVConfig struct {
+	// Delimiter разделитель полей: один символ, "tab" или пусто - определить по первой строке
+	// (для .tsv - табуляция)
+	Delimiter string
+	// Quote символ кавычек вокруг значений с разделителями и переводами строк: по умолчанию ",
+	// none - без кавычек
+	Quote string
+	// Encoding кодировка файла: auto (по умолчанию), utf-8, cp1251 или koi8-r.
+	// В режиме auto UTF-8 (в том числе с BOM) определяется по содержимому,
+	// а Windows-1251 и KOI8-R - по частоте строчных и заглавных русских букв
+	Encoding string
+}
+
+// IsCSV сообщает, что excel.file - текстовая таблица, а не книга Excel
+func (c ExcelConfig) IsCSV() bool {
+	switch strings.ToLower(filepath.Ext(c.File)) {
+	case ".csv", ".tsv", ".txt":
+		return true
+	}
+	return false
+}
+
+// DelimiterRune возвращает разделитель полей; 0 - определить по содержимому файла
+func (c CSVConfig) DelimiterRune() rune {
+	if strings.EqualFold(c.Delimiter, "tab") || c.Delimiter == `\t` {
+		return '\t'
+	}
+	r, _ := utf8.DecodeRuneInString(c.Delimiter)
+	if r == utf8.RuneError {
+		return 0
+	}
+	return r
+}
+
+// QuoteRune возвращает символ кавычек; 0 - значения без кавычек
+func (c CSVConfig) QuoteRune() rune {
+	if c.Quote == QuoteNone {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(c.Quote)
+	return r
+}
+
+// loadCSV читает раздел excel.csv
+func loadCSV(v *viper.Viper) CSVConfig {
+	return CSVConfig{
+		Delimiter: v.GetString("excel.csv.delimiter"),
+		Quote:     strings.TrimSpace(v.GetString("excel.csv.quote")),
+		Encoding:  strings.ToLower(strings.TrimSpace(v.GetString("excel.csv.encoding"))),
+	}
+}
+
+// validateCSV проверяет параметры CSV и задаёт значения по умолчанию
+func (c *ExcelConfig) validateCSV() error {
+	if !c.IsCSV() {
+		return nil
+	}
+	csv := &c.CSV
+
+	if csv.Delimiter == "" && strings.EqualFold(filepath.Ext(c.File), ".tsv") {
+		csv.Delimiter = "tab"
+	}
+	if csv.Delimiter != "" && csv.DelimiterRune() != '\t' && utf8.RuneCountInString(csv.Delimiter) != 1 {
+		return fmt.Errorf("разделитель полей (excel.csv.delimiter) должен быть одним символом или tab, получено '%s'", csv.Delimiter)
+	}
+
+	switch {
+	case csv.Quote == "":
+		csv.Quote = `"`
+	case csv.Quote == QuoteNone:
+	case utf8.RuneCountInString(csv.Quote) != 1:
+		return fmt.Errorf("кавычки (excel.csv.quote) должны быть одним символом или none, получено '%s'", csv.Quote)
+	}
+	if delimiter := csv.DelimiterRune(); delimiter != 0 && delimiter == csv.QuoteRune() {
+		return fmt.Errorf("разделитель полей и кавычки (excel.csv) должны различаться")
+	}
+
+	switch csv.Encoding {
+	case "":
+		csv.Encoding = EncodingAuto
+	case "utf8", "utf-8-bom":
+		csv.Encoding = EncodingUTF8
+	case "windows-1251", "1251":
+		csv.Encoding = EncodingCP1251
+	case "koi8r", "koi8":
+		csv.Encoding = EncodingKOI8R
+	case EncodingAuto, EncodingUTF8, EncodingCP1251, EncodingKOI8R:
+	default:
+		return fmt.Errorf("неизвестная кодировка '%s' (excel.csv.encoding): ожидается auto, utf-8, cp1251 или koi8-r", csv.Encoding)
+	}
+	return nil
+}
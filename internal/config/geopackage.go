@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// GeoPackageConfig настройки записи результата в GeoPackage (geojson.output с расширением .gpkg)
+type GeoPackageConfig struct {
+	// LayerProperty свойство объекта, по значению которого объекты раскладываются по слоям;
+	// пустая строка - отдельный слой для каждого типа геометрии
+	LayerProperty string
+}
+
+// loadGeoPackage читает раздел geopackage
+func loadGeoPackage(v *viper.Viper) GeoPackageConfig {
+	return GeoPackageConfig{
+		LayerProperty: strings.TrimSpace(v.GetString("geopackage.layer_property")),
+	}
+}
+
+// validateGeoPackage проверяет, что настройки GeoPackage применимы к выходному файлу
+func (c *Config) validateGeoPackage() error {
+	if c.GeoPackage.LayerProperty == "" {
+		return nil
+	}
+	if !strings.EqualFold(filepath.Ext(c.Geojson.Output), ".gpkg") {
+		return fmt.Errorf("geopackage.layer_property применяется только к выходному файлу .gpkg (geojson.output)")
+	}
+	return nil
+}
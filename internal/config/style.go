@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/spf13/viper"
+)
+
+// styleOptions сопоставляет ключи YAML (appearance.*) с ключами оформления GeoJSON.
+// marker_color задаётся отдельно (appearance.marker_color, color_column и color_rules)
+var styleOptions = map[string]string{
+	"marker_symbol":  models.StyleMarkerSymbol,
+	"marker_size":    models.StyleMarkerSize,
+	"icon_content":   models.StyleIconContent,
+	"preset":         models.StylePreset,
+	"stroke":         models.StyleStroke,
+	"stroke_width":   models.StyleStrokeWidth,
+	"stroke_opacity": models.StyleStrokeOpacity,
+	"fill":           models.StyleFill,
+	"fill_opacity":   models.StyleFillOpacity,
+}
+
+// StyleOptionName возвращает ключ YAML (appearance.*) для параметра оформления GeoJSON
+func StyleOptionName(key string) (string, bool) {
+	if key == models.StyleMarkerColor {
+		return "marker_color", true
+	}
+	for option, k := range styleOptions {
+		if k == key {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeStyleValue проверяет значение параметра оформления и приводит его к типу,
+// который ожидает simplestyle-spec: цвета - #RRGGBB, толщина и прозрачность - числа
+func NormalizeStyleValue(key, value string) (any, error) {
+	value = strings.TrimSpace(value)
+
+	switch key {
+	case models.StyleMarkerColor, models.StyleStroke, models.StyleFill:
+		return NormalizeColor(value)
+	case models.StyleMarkerSize:
+		size := strings.ToLower(value)
+		if size != "small" && size != "medium" && size != "large" {
+			return nil, fmt.Errorf("размер маркера '%s' должен быть small, medium или large", value)
+		}
+		return size, nil
+	case models.StyleStrokeWidth, models.StyleStrokeOpacity, models.StyleFillOpacity:
+		num, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("значение %s '%s' должно быть числом", key, value)
+		}
+		if num < 0 || (key != models.StyleStrokeWidth && num > 1) {
+			return nil, fmt.Errorf("значение %s '%s' вне допустимого диапазона", key, value)
+		}
+		return num, nil
+	}
+
+	return value, nil
+}
+
+// loadStyle читает статические параметры оформления и столбцы оформления для каждой строки
+func loadStyle(v *viper.Viper, a *AppearanceConfig) error {
+	for option, key := range styleOptions {
+		if raw := v.GetString("appearance." + option); raw != "" {
+			value, err := NormalizeStyleValue(key, raw)
+			if err != nil {
+				return fmt.Errorf("appearance.%s: %w", option, err)
+			}
+			a.Style.Set(key, value)
+		}
+
+		ref, err := loadColumnRef(v, "appearance.columns."+option)
+		if err != nil {
+			return err
+		}
+		if ref.IsSet() {
+			if a.StyleColumns == nil {
+				a.StyleColumns = make(map[string]ColumnRef)
+			}
+			a.StyleColumns[key] = ref
+		}
+	}
+	return nil
+}
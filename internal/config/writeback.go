@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/xuri/excelize/v2"
+)
+
+// Значения, которые можно дописать в исходную книгу (writeback.columns[].value)
+const (
+	WriteBackStatus    = "status"
+	WriteBackMessage   = "message"
+	WriteBackAction    = "action"
+	WriteBackID        = "id"
+	WriteBackLatitude  = "latitude"
+	WriteBackLongitude = "longitude"
+	WriteBackWKT       = "wkt"
+	WriteBackZone      = "zone"
+	WriteBackProperty  = "property"
+)
+
+// WriteBackColumn столбец с результатом обработки строки
+type WriteBackColumn struct {
+	// Value что записывается: status, message, action, id, latitude, longitude, wkt, zone или property
+	Value string
+	// Property имя свойства объекта для Value = property
+	Property string
+	// Header заголовок столбца; столбец с таким заголовком используется повторно
+	Header string
+	// Column буква столбца; пустая строка - столбец с заголовком Header или первый свободный
+	Column string
+}
+
+// WriteBackConfig запись результатов обработки в копию исходной книги или в неё саму
+type WriteBackConfig struct {
+	// Output путь к копии исходной книги с дописанными столбцами
+	Output string
+	// InPlace дописывает столбцы в саму исходную книгу excel.file
+	InPlace bool
+	Columns []WriteBackColumn
+}
+
+// Enabled сообщает, включена ли запись результатов в книгу
+func (w WriteBackConfig) Enabled() bool {
+	return len(w.Columns) > 0
+}
+
+// loadWriteBack читает раздел writeback
+func loadWriteBack(v *viper.Viper) (WriteBackConfig, error) {
+	cfg := WriteBackConfig{
+		Output:  strings.TrimSpace(v.GetString("writeback.output")),
+		InPlace: v.GetBool("writeback.in_place"),
+	}
+
+	var raw []struct {
+		Value    string `mapstructure:"value"`
+		Property string `mapstructure:"property"`
+		Header   string `mapstructure:"header"`
+		Column   string `mapstructure:"column"`
+	}
+	if err := v.UnmarshalKey("writeback.columns", &raw); err != nil {
+		return cfg, fmt.Errorf("не удалось прочитать writeback.columns: %w", err)
+	}
+	for _, item := range raw {
+		cfg.Columns = append(cfg.Columns, WriteBackColumn{
+			Value:    strings.ToLower(strings.TrimSpace(item.Value)),
+			Property: strings.TrimSpace(item.Property),
+			Header:   strings.TrimSpace(item.Header),
+			Column:   strings.ToUpper(strings.TrimSpace(item.Column)),
+		})
+	}
+	return cfg, nil
+}
+
+// validateWriteBack проверяет настройки записи результатов в книгу
+func (c *Config) validateWriteBack() error {
+	w := &c.WriteBack
+	if !w.Enabled() {
+		if w.Output != "" || w.InPlace {
+			return fmt.Errorf("не указаны столбцы для записи результатов (writeback.columns)")
+		}
+		return nil
+	}
+
+	switch {
+	case c.Excel.IsCSV():
+		return fmt.Errorf("результаты строк записываются только в книгу .xlsx, а excel.file - таблица CSV (writeback)")
+	case w.InPlace && w.Output != "":
+		return fmt.Errorf("укажите либо writeback.output, либо writeback.in_place")
+	case w.InPlace:
+		if !strings.EqualFold(filepath.Ext(c.Excel.File), ".xlsx") {
+			return fmt.Errorf("результаты можно дописать только в книгу .xlsx (writeback.in_place)")
+		}
+	case w.Output == "":
+		return fmt.Errorf("не указан путь к копии книги (writeback.output) или writeback.in_place")
+	case !strings.EqualFold(filepath.Ext(w.Output), ".xlsx"):
+		return fmt.Errorf("копия книги с результатами (writeback.output) должна быть файлом .xlsx")
+	case filepath.Clean(w.Output) == filepath.Clean(c.Excel.File):
+		return fmt.Errorf("writeback.output совпадает с исходной книгой: чтобы изменить её, укажите writeback.in_place: true")
+	case c.Rejected.Highlight != "" && filepath.Clean(w.Output) == filepath.Clean(c.Rejected.Highlight):
+		return fmt.Errorf("writeback.output не может совпадать с копией книги rejected.highlight")
+	}
+
+	used := make(map[string]int)
+	for i := range w.Columns {
+		col := &w.Columns[i]
+		key := fmt.Sprintf("writeback.columns[%d]", i)
+
+		switch col.Value {
+		case WriteBackStatus, WriteBackMessage, WriteBackAction, WriteBackWKT:
+		case WriteBackID:
+			// Идентификатор берётся из столбца excel.columns.id или из объекта, обновлённого по ключу;
+			// без них столбец был бы пустым
+			if !c.Excel.Columns.ID.IsSet() && !c.Geojson.Upsert.Enabled() {
+				return fmt.Errorf("%s: для записи идентификатора укажите столбец excel.columns.id или geojson.upsert", key)
+			}
+		case WriteBackLatitude, WriteBackLongitude:
+			if c.Excel.Geometry.Type != GeometryPoint {
+				return fmt.Errorf("%s: значение %s доступно только для точек (excel.geometry.type: point)", key, col.Value)
+			}
+		case WriteBackZone:
+			if !c.Zones.Enabled() {
+				return fmt.Errorf("%s: для записи зоны включите привязку к зонам (zones.property)", key)
+			}
+		case WriteBackProperty:
+			if col.Property == "" {
+				return fmt.Errorf("%s: не указано имя свойства (property)", key)
+			}
+		case "":
+			return fmt.Errorf("%s: не указано значение (value)", key)
+		default:
+			return fmt.Errorf("%s: неизвестное значение '%s': ожидается status, message, action, id, latitude, longitude, wkt, zone или property", key, col.Value)
+		}
+
+		if col.Header == "" {
+			col.Header = col.Value
+			if col.Value == WriteBackProperty {
+				col.Header = col.Property
+			}
+		}
+		if col.Column != "" {
+			if _, err := excelize.ColumnNameToNumber(col.Column); err != nil {
+				return fmt.Errorf("%s: неверная буква столбца '%s'", key, col.Column)
+			}
+		}
+
+		name := strings.ToLower(col.Header)
+		if col.Column != "" {
+			name = col.Column
+		}
+		if first, ok := used[name]; ok {
+			return fmt.Errorf("%s: столбец совпадает со столбцом writeback.columns[%d]", key, first)
+		}
+		used[name] = i
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Package crs переводит координаты между системами координат и WGS 84 (EPSG:4326).
+//
+// Поддерживаются системы, с которыми приходят данные съёмки:
+//
+//	EPSG:4326          WGS 84, градусы
+//	EPSG:4284          СК-42 (Пулково 1942), градусы
+//	EPSG:28402-28432   СК-42 / Гаусс-Крюгер, зоны 2-32 (номер зоны в начале восточной координаты)
+//	EPSG:28462-28492   СК-42 / Гаусс-Крюгер, зоны 2N-32N (восточная координата без номера зоны)
+//	EPSG:7683          ГСК-2011, градусы
+//	GSK2011-GK<зона>   ГСК-2011 / Гаусс-Крюгер (номер зоны в начале восточной координаты)
+//	EPSG:32601-32660   WGS 84 / UTM, северное полушарие
+//	EPSG:32701-32760   WGS 84 / UTM, южное полушарие
+//	EPSG:3857          WGS 84 / Pseudo-Mercator (веб-карты)
+//
+// Датумы переводятся в WGS 84 7-параметрическим преобразованием Гельмерта.
+// Для СК-42 используются параметры ГОСТ Р 51794-2008 (точность порядка метра),
+// ГСК-2011 совпадает с WGS 84 с точностью до дециметров, и сдвиг датума не применяется.
+// Высота не пересчитывается: третья координата переносится без изменений.
+// Описание системы из файла .prj шейп-файла разбирается функцией ParsePRJ
+package crs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CRS система координат
+type CRS struct {
+	// Code код EPSG или обозначение системы
+	Code string
+	// Name название системы
+	Name string
+	// ellipsoid и datum - эллипсоид и преобразование в WGS 84
+	ellipsoid Ellipsoid
+	datum     Helmert
+	// proj проекция; nil для географических систем (координаты в градусах)
+	proj projection
+	// zonePrefix номер зоны, с которого начинается восточная координата (0 - без номера зоны)
+	zonePrefix int
+}
+
+// Датумы поддерживаемых систем
+var (
+	pulkovo1942 = Helmert{Dx: 23.57, Dy: -140.95, Dz: -79.8, Rx: 0, Ry: 0.35, Rz: 0.79, Scale: -0.22}
+	gsk2011     = Helmert{}
+)
+
+// WGS84CRS географическая система WGS 84 - система координат GeoJSON
+var WGS84CRS = &CRS{Code: "EPSG:4326", Name: "WGS 84", ellipsoid: WGS84}
+
+var gskCode = regexp.MustCompile(`^GSK2011-GK(\d{1,2})$`)
+
+// Parse находит систему координат по коду: "EPSG:28407", "28407" или "GSK2011-GK7"
+func Parse(text string) (*CRS, error) {
+	code := strings.ToUpper(strings.TrimSpace(text))
+	if code == "" {
+		return nil, fmt.Errorf("не указан код системы координат")
+	}
+
+	if m := gskCode.FindStringSubmatch(code); m != nil {
+		zone, _ := strconv.Atoi(m[1])
+		if zone < 1 || zone > 60 {
+			return nil, fmt.Errorf("неверный номер зоны Гаусса-Крюгера %d", zone)
+		}
+		return gaussKruger(code, fmt.Sprintf("ГСК-2011 / Гаусс-Крюгер, зона %d", zone), GSK2011, gsk2011, zone, true), nil
+	}
+
+	num, err := strconv.Atoi(strings.TrimPrefix(code, "EPSG:"))
+	if err != nil {
+		return nil, fmt.Errorf("неизвестная система координат '%s': ожидается код EPSG (например, EPSG:28407) или GSK2011-GK<зона>", text)
+	}
+	epsg := fmt.Sprintf("EPSG:%d", num)
+
+	switch {
+	case num == 4326:
+		return WGS84CRS, nil
+	case num == 4284:
+		return &CRS{Code: epsg, Name: "СК-42 (Пулково 1942)", ellipsoid: Krassowsky, datum: pulkovo1942}, nil
+	case num == 7683:
+		return &CRS{Code: epsg, Name: "ГСК-2011", ellipsoid: GSK2011, datum: gsk2011}, nil
+	case num >= 28402 && num <= 28432:
+		zone := num - 28400
+		return gaussKruger(epsg, fmt.Sprintf("СК-42 / Гаусс-Крюгер, зона %d", zone), Krassowsky, pulkovo1942, zone, true), nil
+	case num >= 28462 && num <= 28492:
+		zone := num - 28460
+		return gaussKruger(epsg, fmt.Sprintf("СК-42 / Гаусс-Крюгер, зона %dN", zone), Krassowsky, pulkovo1942, zone, false), nil
+	case num >= 32601 && num <= 32660:
+		return utm(epsg, num-32600, false), nil
+	case num >= 32701 && num <= 32760:
+		return utm(epsg, num-32700, true), nil
+	case num == 3857:
+		return &CRS{Code: epsg, Name: "WGS 84 / Pseudo-Mercator", ellipsoid: WGS84, proj: webMercator{}}, nil
+	}
+	return nil, fmt.Errorf("система координат %s не поддерживается", epsg)
+}
+
+// gaussKruger создаёт систему в проекции Гаусса-Крюгера с 6-градусными зонами
+func gaussKruger(code, name string, ellipsoid Ellipsoid, datum Helmert, zone int, zonePrefix bool) *CRS {
+	falseEasting, prefix := 500000.0, 0
+	if zonePrefix {
+		falseEasting += float64(zone) * 1e6
+		prefix = zone
+	}
+	return &CRS{
+		Code:       code,
+		Name:       name,
+		ellipsoid:  ellipsoid,
+		datum:      datum,
+		zonePrefix: prefix,
+		proj: transverseMercator{
+			ellipsoid:    ellipsoid,
+			lon0:         float64(6*zone - 3),
+			k0:           1,
+			falseEasting: falseEasting,
+		},
+	}
+}
+
+// utm создаёт систему WGS 84 / UTM
+func utm(code string, zone int, south bool) *CRS {
+	hemisphere, falseNorthing := "N", 0.0
+	if south {
+		hemisphere, falseNorthing = "S", 10000000
+	}
+	return &CRS{
+		Code:      code,
+		Name:      fmt.Sprintf("WGS 84 / UTM, зона %d%s", zone, hemisphere),
+		ellipsoid: WGS84,
+		proj: transverseMercator{
+			ellipsoid:     WGS84,
+			lon0:          float64(6*zone - 183),
+			k0:            0.9996,
+			falseEasting:  500000,
+			falseNorthing: falseNorthing,
+		},
+	}
+}
+
+// IsProjected сообщает, что координаты системы - прямоугольные (метры), а не градусы
+func (c *CRS) IsProjected() bool {
+	return c.proj != nil
+}
+
+// IsWGS84 сообщает, что система совпадает с системой координат GeoJSON
+func (c *CRS) IsWGS84() bool {
+	return c == WGS84CRS
+}
+
+// String возвращает код и название системы
+func (c *CRS) String() string {
+	return fmt.Sprintf("%s (%s)", c.Code, c.Name)
+}
+
+// ToWGS84 переводит координату системы в [долгота, широта(, высота)] WGS 84.
+// Для прямоугольных систем pos - [восток, север(, высота)], для географических - [долгота, широта(, высота)]
+func (c *CRS) ToWGS84(pos []float64) []float64 {
+	lon, lat := pos[0], pos[1]
+	if c.proj != nil {
+		lon, lat = c.proj.inverse(pos[0], pos[1])
+	}
+	if !c.datum.isZero() {
+		x, y, z := c.ellipsoid.toECEF(lon, lat, 0)
+		x, y, z = c.datum.apply(x, y, z, false)
+		lon, lat, _ = WGS84.fromECEF(x, y, z)
+	}
+	return append([]float64{lon, lat}, pos[2:]...)
+}
+
+// FromWGS84 переводит [долгота, широта(, высота)] WGS 84 в координату системы
+func (c *CRS) FromWGS84(pos []float64) []float64 {
+	lon, lat := pos[0], pos[1]
+	if !c.datum.isZero() {
+		x, y, z := WGS84.toECEF(lon, lat, 0)
+		x, y, z = c.datum.apply(x, y, z, true)
+		lon, lat, _ = c.ellipsoid.fromECEF(x, y, z)
+	}
+	if c.proj != nil {
+		lon, lat = c.proj.forward(lon, lat)
+	}
+	return append([]float64{lon, lat}, pos[2:]...)
+}
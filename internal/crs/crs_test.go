@@ -0,0 +1,64 @@
+package crs
+
+import (
+	"math"
+	"testing"
+)
+
+// Контрольные точки: WGS 84 и прямоугольные координаты, посчитанные независимо
+// (ряды Крюгера для UTM и Гаусса-Крюгера, параметры ГОСТ Р 51794-2008 для СК-42)
+var projectedCases = []struct {
+	name    string
+	code    string
+	wgs84   []float64
+	planar  []float64
+	planTol float64
+}{
+	{"UTM 37N Москва", "EPSG:32637", []float64{37.6173, 55.7558}, []float64{413224.138, 6179766.954}, 0.01},
+	{"UTM 37N Ростов-на-Дону", "EPSG:32637", []float64{39.7015, 47.2357}, []float64{553096.850, 5231595.776}, 0.01},
+	{"СК-42 ГК зона 7 Москва", "EPSG:28407", []float64{37.6173, 55.7558}, []float64{7413305.557, 6182341.067}, 0.01},
+	{"СК-42 ГК зона 7 Ростов-на-Дону", "EPSG:28407", []float64{39.7015, 47.2357}, []float64{7553230.854, 5233789.441}, 0.01},
+}
+
+func TestFromWGS84(t *testing.T) {
+	for _, tc := range projectedCases {
+		t.Run(tc.name, func(t *testing.T) {
+			system, err := Parse(tc.code)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tc.code, err)
+			}
+			got := system.FromWGS84(tc.wgs84)
+			if math.Abs(got[0]-tc.planar[0]) > tc.planTol || math.Abs(got[1]-tc.planar[1]) > tc.planTol {
+				t.Errorf("FromWGS84(%v) = %.3f, %.3f; ожидается %.3f, %.3f", tc.wgs84, got[0], got[1], tc.planar[0], tc.planar[1])
+			}
+		})
+	}
+}
+
+func TestToWGS84(t *testing.T) {
+	// 1e-7 градуса - около сантиметра
+	const tol = 1e-7
+	for _, tc := range projectedCases {
+		t.Run(tc.name, func(t *testing.T) {
+			system, err := Parse(tc.code)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tc.code, err)
+			}
+			got := system.ToWGS84(tc.planar)
+			if math.Abs(got[0]-tc.wgs84[0]) > tol || math.Abs(got[1]-tc.wgs84[1]) > tol {
+				t.Errorf("ToWGS84(%v) = %.9f, %.9f; ожидается %.9f, %.9f", tc.planar, got[0], got[1], tc.wgs84[0], tc.wgs84[1])
+			}
+		})
+	}
+}
+
+func TestHeightIsKept(t *testing.T) {
+	system, err := Parse("EPSG:28407")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := system.ToWGS84(system.FromWGS84([]float64{37.6173, 55.7558, 150}))
+	if len(got) != 3 || got[2] != 150 {
+		t.Errorf("высота не сохранилась: %v", got)
+	}
+}
@@ -0,0 +1,55 @@
+package crs
+
+import "math"
+
+// Ellipsoid эллипсоид: большая полуось и сжатие
+type Ellipsoid struct {
+	Name string
+	A    float64
+	F    float64
+}
+
+// Эллипсоиды поддерживаемых систем координат
+var (
+	WGS84       = Ellipsoid{Name: "WGS 84", A: 6378137, F: 1 / 298.257223563}
+	Krassowsky  = Ellipsoid{Name: "Красовского 1940", A: 6378245, F: 1 / 298.3}
+	GSK2011     = Ellipsoid{Name: "ГСК-2011", A: 6378136.5, F: 1 / 298.2564151}
+	sphereWGS84 = Ellipsoid{Name: "сфера WGS 84", A: 6378137}
+)
+
+// e2 квадрат первого эксцентриситета
+func (e Ellipsoid) e2() float64 {
+	return e.F * (2 - e.F)
+}
+
+// toECEF переводит геодезические координаты (градусы, метры) в геоцентрические XYZ
+func (e Ellipsoid) toECEF(lon, lat, h float64) (x, y, z float64) {
+	phi, lam := lat*math.Pi/180, lon*math.Pi/180
+	e2 := e.e2()
+	sinPhi := math.Sin(phi)
+	n := e.A / math.Sqrt(1-e2*sinPhi*sinPhi)
+	x = (n + h) * math.Cos(phi) * math.Cos(lam)
+	y = (n + h) * math.Cos(phi) * math.Sin(lam)
+	z = (n*(1-e2) + h) * sinPhi
+	return x, y, z
+}
+
+// fromECEF переводит геоцентрические XYZ в геодезические координаты (итерации по широте)
+func (e Ellipsoid) fromECEF(x, y, z float64) (lon, lat, h float64) {
+	e2 := e.e2()
+	p := math.Hypot(x, y)
+	lam := math.Atan2(y, x)
+	phi := math.Atan2(z, p*(1-e2))
+	for i := 0; i < 10; i++ {
+		sinPhi := math.Sin(phi)
+		n := e.A / math.Sqrt(1-e2*sinPhi*sinPhi)
+		h = p/math.Cos(phi) - n
+		next := math.Atan2(z, p*(1-e2*n/(n+h)))
+		if math.Abs(next-phi) < 1e-12 {
+			phi = next
+			break
+		}
+		phi = next
+	}
+	return lam * 180 / math.Pi, phi * 180 / math.Pi, h
+}
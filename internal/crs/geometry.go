@@ -0,0 +1,39 @@
+package crs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// GeometryToWGS84 переводит все координаты геометрии из системы c в WGS 84.
+// Ошибка означает, что координаты не похожи на координаты системы c
+// (например, перепутаны X и Y или выбрана не та зона)
+func (c *CRS) GeometryToWGS84(g models.Geometry) (models.Geometry, error) {
+	var outOfRange, wrongZone []float64
+	result := models.MapPositions(g, func(pos []float64) []float64 {
+		if wrongZone == nil && c.zonePrefix > 0 && int(pos[0]/1e6) != c.zonePrefix {
+			wrongZone = pos
+		}
+		converted := c.ToWGS84(pos)
+		lon, lat := converted[0], converted[1]
+		if outOfRange == nil && (math.IsNaN(lon) || math.IsNaN(lat) || math.Abs(lon) > 180 || math.Abs(lat) > 90) {
+			outOfRange = pos
+		}
+		return converted
+	})
+	if wrongZone != nil {
+		return nil, fmt.Errorf("восточная координата %.2f не начинается с номера зоны %d (%s): проверьте зону и порядок X Y",
+			wrongZone[0], c.zonePrefix, c.Code)
+	}
+	if outOfRange != nil {
+		return nil, fmt.Errorf("координаты %v вне области определения системы %s", outOfRange[:2], c.Code)
+	}
+	return result, nil
+}
+
+// GeometryFromWGS84 переводит все координаты геометрии из WGS 84 в систему c
+func (c *CRS) GeometryFromWGS84(g models.Geometry) models.Geometry {
+	return models.MapPositions(g, c.FromWGS84)
+}
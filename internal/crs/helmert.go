@@ -0,0 +1,34 @@
+package crs
+
+import "math"
+
+// Helmert параметры 7-параметрического преобразования датума в WGS 84
+// в соглашении position vector (как +towgs84 в PROJ): сдвиги в метрах, повороты в угловых секундах,
+// масштаб в миллионных долях
+type Helmert struct {
+	Dx, Dy, Dz float64
+	Rx, Ry, Rz float64
+	Scale      float64
+}
+
+const arcSecond = math.Pi / (180 * 3600)
+
+// apply преобразует геоцентрические координаты датума в WGS 84; inverse - обратно
+func (t Helmert) apply(x, y, z float64, inverse bool) (float64, float64, float64) {
+	dx, dy, dz := t.Dx, t.Dy, t.Dz
+	rx, ry, rz := t.Rx*arcSecond, t.Ry*arcSecond, t.Rz*arcSecond
+	m := 1 + t.Scale*1e-6
+	if inverse {
+		// Для малых поворотов обратное преобразование - преобразование с параметрами противоположного знака
+		dx, dy, dz, rx, ry, rz = -dx, -dy, -dz, -rx, -ry, -rz
+		m = 1 / m
+	}
+	return dx + m*(x-rz*y+ry*z),
+		dy + m*(rz*x+y-rx*z),
+		dz + m*(-ry*x+rx*y+z)
+}
+
+// isZero сообщает, что датум совпадает с WGS 84
+func (t Helmert) isZero() bool {
+	return t == Helmert{}
+}
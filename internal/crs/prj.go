@@ -0,0 +1,122 @@
+package crs
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	prjAuthority = regexp.MustCompile(`(?i)AUTHORITY\[\s*"EPSG"\s*,\s*"?(\d+)"?\s*\]\s*\]\s*$`)
+	prjName      = regexp.MustCompile(`^\s*(?i:PROJCS|GEOGCS)\[\s*"([^"]*)"`)
+	prjProj      = regexp.MustCompile(`(?i)PROJECTION\[\s*"([^"]*)"`)
+	prjParameter = regexp.MustCompile(`(?i)PARAMETER\[\s*"([^"]*)"\s*,\s*([-+0-9.eE]+)\s*\]`)
+)
+
+// ParsePRJ находит систему координат по описанию WKT из файла .prj шейп-файла.
+// Сначала используется код EPSG (AUTHORITY), а если его нет (так пишут ArcGIS и MapInfo) -
+// датум и параметры проекции: Гаусс-Крюгер СК-42 и ГСК-2011, UTM и Web Mercator
+func ParsePRJ(text string) (*CRS, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("пустое описание системы координат")
+	}
+	name := text
+	if m := prjName.FindStringSubmatch(text); m != nil {
+		name = m[1]
+	}
+
+	if m := prjAuthority.FindStringSubmatch(text); m != nil {
+		if system, err := Parse("EPSG:" + m[1]); err == nil {
+			return system, nil
+		}
+	}
+
+	lower := strings.ToLower(text)
+	datum := ""
+	switch {
+	case strings.Contains(lower, "pulkovo") || strings.Contains(lower, "krasovsky") || strings.Contains(lower, "krassowsky"):
+		datum = "sk42"
+	case strings.Contains(lower, "gsk") && strings.Contains(lower, "2011"):
+		datum = "gsk2011"
+	case strings.Contains(lower, "wgs") && strings.Contains(lower, "84"):
+		datum = "wgs84"
+	}
+
+	if !strings.HasPrefix(strings.ToUpper(text), "PROJCS") {
+		switch datum {
+		case "wgs84":
+			return WGS84CRS, nil
+		case "sk42":
+			return Parse("EPSG:4284")
+		case "gsk2011":
+			return Parse("EPSG:7683")
+		}
+		return nil, fmt.Errorf("система координат '%s' не поддерживается", name)
+	}
+
+	projection := ""
+	if m := prjProj.FindStringSubmatch(text); m != nil {
+		projection = strings.ToLower(m[1])
+	}
+	params := make(map[string]float64)
+	for _, m := range prjParameter.FindAllStringSubmatch(text, -1) {
+		if value, err := strconv.ParseFloat(m[2], 64); err == nil {
+			params[strings.ToLower(m[1])] = value
+		}
+	}
+
+	switch {
+	case datum == "wgs84" && (strings.Contains(projection, "auxiliary_sphere") || strings.Contains(projection, "pseudo") ||
+		strings.Contains(projection, "popular_visualisation")):
+		return Parse("EPSG:3857")
+	case strings.Contains(projection, "transverse_mercator") || strings.Contains(projection, "gauss_kruger"):
+		if code := transverseMercatorCode(datum, params); code != "" {
+			return Parse(code)
+		}
+	}
+	return nil, fmt.Errorf("система координат '%s' не поддерживается", name)
+}
+
+// transverseMercatorCode подбирает код системы по датуму и параметрам поперечной проекции Меркатора;
+// пустая строка - параметры не совпадают ни с одной поддерживаемой зоной
+func transverseMercatorCode(datum string, params map[string]float64) string {
+	centralMeridian := params["central_meridian"]
+	falseEasting := params["false_easting"]
+	falseNorthing := params["false_northing"]
+	scale, ok := params["scale_factor"]
+	if !ok {
+		scale = 1
+	}
+
+	switch datum {
+	case "sk42", "gsk2011":
+		zone := (centralMeridian + 3) / 6
+		if zone != math.Trunc(zone) || zone < 1 || zone > 60 || scale != 1 || falseNorthing != 0 {
+			return ""
+		}
+		prefixed := falseEasting == zone*1e6+500000
+		switch {
+		case datum == "gsk2011" && prefixed:
+			return fmt.Sprintf("GSK2011-GK%d", int(zone))
+		case datum == "sk42" && prefixed:
+			return fmt.Sprintf("EPSG:%d", 28400+int(zone))
+		case datum == "sk42" && falseEasting == 500000:
+			return fmt.Sprintf("EPSG:%d", 28460+int(zone))
+		}
+	case "wgs84":
+		zone := (centralMeridian + 183) / 6
+		if zone != math.Trunc(zone) || zone < 1 || zone > 60 || scale != 0.9996 || falseEasting != 500000 {
+			return ""
+		}
+		switch falseNorthing {
+		case 0:
+			return fmt.Sprintf("EPSG:%d", 32600+int(zone))
+		case 10000000:
+			return fmt.Sprintf("EPSG:%d", 32700+int(zone))
+		}
+	}
+	return ""
+}
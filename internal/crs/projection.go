@@ -0,0 +1,93 @@
+package crs
+
+import "math"
+
+// projection картографическая проекция: перевод градусов в метры и обратно на эллипсоиде датума
+type projection interface {
+	forward(lon, lat float64) (x, y float64)
+	inverse(x, y float64) (lon, lat float64)
+}
+
+// transverseMercator поперечная проекция Меркатора (Гаусса-Крюгера, UTM).
+// Ряды Снайдера (USGS Professional Paper 1395) дают миллиметровую точность в пределах 6-градусной зоны
+type transverseMercator struct {
+	ellipsoid     Ellipsoid
+	lon0          float64
+	k0            float64
+	falseEasting  float64
+	falseNorthing float64
+}
+
+func (p transverseMercator) forward(lon, lat float64) (float64, float64) {
+	a, e2 := p.ellipsoid.A, p.ellipsoid.e2()
+	ep2 := e2 / (1 - e2)
+	phi := lat * math.Pi / 180
+	dl := (lon - p.lon0) * math.Pi / 180
+
+	sinPhi, cosPhi, tanPhi := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+	n := a / math.Sqrt(1-e2*sinPhi*sinPhi)
+	t := tanPhi * tanPhi
+	c := ep2 * cosPhi * cosPhi
+	A := dl * cosPhi
+	m := meridianArc(a, e2, phi)
+
+	x := p.k0 * n * (A + (1-t+c)*math.Pow(A, 3)/6 +
+		(5-18*t+t*t+72*c-58*ep2)*math.Pow(A, 5)/120)
+	y := p.k0 * (m + n*tanPhi*(A*A/2+(5-t+9*c+4*c*c)*math.Pow(A, 4)/24+
+		(61-58*t+t*t+600*c-330*ep2)*math.Pow(A, 6)/720))
+	return x + p.falseEasting, y + p.falseNorthing
+}
+
+func (p transverseMercator) inverse(x, y float64) (float64, float64) {
+	a, e2 := p.ellipsoid.A, p.ellipsoid.e2()
+	ep2 := e2 / (1 - e2)
+	x -= p.falseEasting
+	y -= p.falseNorthing
+
+	m := y / p.k0
+	mu := m / (a * (1 - e2/4 - 3*e2*e2/64 - 5*e2*e2*e2/256))
+	e1 := (1 - math.Sqrt(1-e2)) / (1 + math.Sqrt(1-e2))
+	phi1 := mu + (3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+	sinPhi1, cosPhi1, tanPhi1 := math.Sin(phi1), math.Cos(phi1), math.Tan(phi1)
+	c1 := ep2 * cosPhi1 * cosPhi1
+	t1 := tanPhi1 * tanPhi1
+	n1 := a / math.Sqrt(1-e2*sinPhi1*sinPhi1)
+	r1 := a * (1 - e2) / math.Pow(1-e2*sinPhi1*sinPhi1, 1.5)
+	d := x / (n1 * p.k0)
+
+	phi := phi1 - (n1*tanPhi1/r1)*(d*d/2-
+		(5+3*t1+10*c1-4*c1*c1-9*ep2)*math.Pow(d, 4)/24+
+		(61+90*t1+298*c1+45*t1*t1-252*ep2-3*c1*c1)*math.Pow(d, 6)/720)
+	lam := (d - (1+2*t1+c1)*math.Pow(d, 3)/6 +
+		(5-2*c1+28*t1-3*c1*c1+8*ep2+24*t1*t1)*math.Pow(d, 5)/120) / cosPhi1
+
+	return p.lon0 + lam*180/math.Pi, phi * 180 / math.Pi
+}
+
+// meridianArc длина дуги меридиана от экватора до широты phi (радианы)
+func meridianArc(a, e2, phi float64) float64 {
+	e4, e6 := e2*e2, e2*e2*e2
+	return a * ((1-e2/4-3*e4/64-5*e6/256)*phi -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*phi) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*phi) -
+		(35*e6/3072)*math.Sin(6*phi))
+}
+
+// webMercator сферическая проекция Меркатора веб-карт (EPSG:3857)
+type webMercator struct{}
+
+func (webMercator) forward(lon, lat float64) (float64, float64) {
+	r := sphereWGS84.A
+	return r * lon * math.Pi / 180,
+		r * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+}
+
+func (webMercator) inverse(x, y float64) (float64, float64) {
+	r := sphereWGS84.A
+	return x / r * 180 / math.Pi,
+		(2*math.Atan(math.Exp(y/r)) - math.Pi/2) * 180 / math.Pi
+}
@@ -0,0 +1,111 @@
+// Package filter отбирает объекты GeoJSON по значениям свойств.
+//
+// Условие записывается как "ключ=значение" (точное совпадение без учёта регистра)
+// или "ключ~подстрока" (вхождение подстроки без учёта регистра).
+// Ключ id сравнивается с идентификатором объекта, если у объекта нет свойства id
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// Операции сравнения
+const (
+	OpEquals   = "="
+	OpContains = "~"
+)
+
+// Condition условие на значение одного свойства
+type Condition struct {
+	Key   string
+	Op    string
+	Value string
+}
+
+// Parse разбирает условие "ключ=значение" или "ключ~подстрока".
+// Кавычки вокруг значения отбрасываются: description~"закрыт"
+func Parse(expr string) (Condition, error) {
+	idx := strings.IndexAny(expr, OpEquals+OpContains)
+	if idx <= 0 {
+		return Condition{}, fmt.Errorf("условие '%s' должно иметь вид ключ=значение или ключ~подстрока", expr)
+	}
+
+	key := strings.TrimSpace(expr[:idx])
+	if key == "" {
+		return Condition{}, fmt.Errorf("в условии '%s' не указан ключ", expr)
+	}
+	return Condition{
+		Key:   key,
+		Op:    expr[idx : idx+1],
+		Value: unquote(strings.TrimSpace(expr[idx+1:])),
+	}, nil
+}
+
+// ParseAll разбирает список условий
+func ParseAll(exprs []string) ([]Condition, error) {
+	conditions := make([]Condition, 0, len(exprs))
+	for _, expr := range exprs {
+		cond, err := Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+	}
+	return conditions, nil
+}
+
+// Match проверяет условие для объекта
+func (c Condition) Match(feature *geojson.Feature) bool {
+	value, ok := feature.Properties[c.Key]
+	if !ok && c.Key == "id" {
+		value, ok = feature.ID, feature.ID != nil
+	}
+	if !ok || value == nil {
+		return false
+	}
+
+	text := valueString(value)
+	switch c.Op {
+	case OpContains:
+		return strings.Contains(strings.ToLower(text), strings.ToLower(c.Value))
+	default:
+		return strings.EqualFold(text, c.Value)
+	}
+}
+
+// String возвращает условие в исходной записи
+func (c Condition) String() string {
+	return c.Key + c.Op + c.Value
+}
+
+// MatchAll проверяет, что объект удовлетворяет всем условиям
+func MatchAll(feature *geojson.Feature, conditions []Condition) bool {
+	for _, cond := range conditions {
+		if !cond.Match(feature) {
+			return false
+		}
+	}
+	return true
+}
+
+// valueString приводит значение свойства к строке; целые числа записываются без дробной части
+func valueString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
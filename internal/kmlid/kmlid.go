@@ -0,0 +1,58 @@
+// Package kmlid переводит идентификаторы объектов в атрибут id элемента Placemark и обратно.
+//
+// Атрибут id в KML - XML ID: он должен начинаться с буквы или _. Идентификатор, который начинается
+// не с буквы (например, число 7), записывается с префиксом Prefix (f7). Префикс получают и
+// идентификаторы, которые иначе были бы приняты при чтении за записанные с префиксом (f7 -> ff7),
+// поэтому Decode(Encode(id)) == id для любого идентификатора.
+//
+// Префикс убирается только в документах, которые записал KMLWriter: он отмечает документ
+// элементом Data с именем Marker. У идентификаторов из других программ (например, f1 у Google Earth)
+// префикс не убирается
+package kmlid
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Prefix префикс идентификатора, который начинается не с буквы
+const Prefix = "f"
+
+// Marker имя элемента Data в ExtendedData документа, которым KMLWriter отмечает идентификаторы
+// с префиксом; значение - Prefix
+const Marker = "jgeo-excel:id-prefix"
+
+// Encode возвращает атрибут id Placemark для идентификатора объекта
+func Encode(id string) string {
+	if needsPrefix(id) {
+		return Prefix + id
+	}
+	return id
+}
+
+// Decode возвращает идентификатор объекта из атрибута id Placemark, записанного Encode
+func Decode(id string) string {
+	if rest, ok := strings.CutPrefix(id, Prefix); ok && needsPrefix(rest) {
+		return rest
+	}
+	return id
+}
+
+// needsPrefix сообщает, что идентификатору нужен префикс Prefix
+func needsPrefix(id string) bool {
+	if id == "" {
+		return false
+	}
+	if !startsWithLetter(id) {
+		return true
+	}
+	rest, ok := strings.CutPrefix(id, Prefix)
+	return ok && needsPrefix(rest)
+}
+
+// startsWithLetter проверяет, что идентификатор начинается с буквы или _, как требует XML ID
+func startsWithLetter(id string) bool {
+	r, _ := utf8.DecodeRuneInString(id)
+	return unicode.IsLetter(r) || r == '_'
+}
@@ -1,42 +1,120 @@
 package models
 
-import (
-	"strconv"
-	"strings"
-)
+import "fmt"
 
+// CordsDataType тип геометрии GeoJSON
 type CordsDataType string
-type CordsListType [][][]float64
 
 const (
-	Point   CordsDataType = "Point"
-	Polygon CordsDataType = "Polygon"
+	Point              CordsDataType = "Point"
+	LineString         CordsDataType = "LineString"
+	Polygon            CordsDataType = "Polygon"
+	MultiPoint         CordsDataType = "MultiPoint"
+	MultiLineString    CordsDataType = "MultiLineString"
+	MultiPolygon       CordsDataType = "MultiPolygon"
+	GeometryCollection CordsDataType = "GeometryCollection"
 )
 
+// CordsData объект с координатами и свойствами
 type CordsData struct {
-	Type        string
+	// ID идентификатор объекта (id объекта GeoJSON), может быть пустым
+	ID          string
 	IconCaption string
 	Description string
-	Cords       any
-	Color       string
+	// Geometry геометрия объекта, nil - объект без геометрии (geometry: null)
+	Geometry Geometry
+	// Style оформление объекта (цвет маркера, обводка, заливка и т.д.)
+	Style Style
+	// Notation формат, в котором координаты были записаны в источнике
+	Notation CordsNotation
+	// Properties дополнительные свойства объекта (адрес, телефон, категория и т.п.)
+	Properties map[string]any
+	// Row номер строки источника (для объекта из нескольких строк - первой), 0 - неизвестен
+	Row int
+	// Rows номера всех строк источника для объекта, собранного из нескольких строк
+	Rows []int
 }
 
-func (c *CordsData) SetCords(cords string) error {
-	// Разделяем строку по запятым, точкам с запятой и пробелам, удаляем пустые элементы
-	parts := strings.FieldsFunc(cords, func(r rune) bool {
-		return r == ',' || r == ';' || r == ' '
-	})
-	floatCords := make([]float64, 0, len(parts))
-	for _, str := range parts {
-		if str == "" {
-			continue
-		}
-		val, err := strconv.ParseFloat(str, 64)
+// GeometryType возвращает тип геометрии объекта или пустую строку для объекта без геометрии
+func (c *CordsData) GeometryType() string {
+	if c.Geometry == nil {
+		return ""
+	}
+	return string(c.Geometry.GeometryType())
+}
+
+// SourceRows возвращает номера строк источника, из которых получен объект
+func (c *CordsData) SourceRows() []int {
+	if len(c.Rows) > 0 {
+		return c.Rows
+	}
+	if c.Row > 0 {
+		return []int{c.Row}
+	}
+	return nil
+}
+
+// SetProperty устанавливает дополнительное свойство объекта
+func (c *CordsData) SetProperty(key string, value any) {
+	if c.Properties == nil {
+		c.Properties = make(map[string]any)
+	}
+	c.Properties[key] = value
+}
+
+// SetCords разбирает строку с координатами в формате "широта долгота" и сохраняет точку
+// в порядке GeoJSON [долгота, широта(, высота)].
+// По умолчанию используется DefaultCordsParser, его можно заменить своим парсером
+func (c *CordsData) SetCords(cords string, parser ...CordsParser) error {
+	var p CordsParser = DefaultCordsParser{}
+	if len(parser) > 0 && parser[0] != nil {
+		p = parser[0]
+	}
+
+	floatCords, notation, err := p.Parse(cords)
+	if err != nil {
+		return err
+	}
+	c.Geometry = &PointGeometry{Coordinates: latLonToPosition(floatCords)}
+	c.Notation = notation
+	return nil
+}
+
+// SetLatLon разбирает координаты, записанные в отдельных ячейках, и сохраняет точку
+// в порядке GeoJSON [долгота, широта(, высота)]. Высота необязательна
+func (c *CordsData) SetLatLon(lat, lon, alt string, parser ...CordsParser) error {
+	var p CordsParser = DefaultCordsParser{}
+	if len(parser) > 0 && parser[0] != nil {
+		p = parser[0]
+	}
+
+	latVal, latNotation, err := p.ParseAxis(lat, AxisLatitude)
+	if err != nil {
+		return fmt.Errorf("широта '%s': %w", lat, err)
+	}
+	lonVal, lonNotation, err := p.ParseAxis(lon, AxisLongitude)
+	if err != nil {
+		return fmt.Errorf("долгота '%s': %w", lon, err)
+	}
+
+	floatCords := []float64{latVal, lonVal}
+	if alt != "" {
+		altVal, _, err := p.ParseAxis(alt, AxisAltitude)
 		if err != nil {
-			return err
+			return fmt.Errorf("высота '%s': %w", alt, err)
 		}
-		floatCords = append(floatCords, val)
+		floatCords = append(floatCords, altVal)
+	}
+
+	c.Geometry = &PointGeometry{Coordinates: latLonToPosition(floatCords)}
+	c.Notation = latNotation
+	if lonNotation == NotationDMS || (lonNotation == NotationDDM && latNotation == NotationDecimal) {
+		c.Notation = lonNotation
 	}
-	c.Cords = floatCords
 	return nil
 }
+
+// latLonToPosition переставляет координаты из [широта, долгота(, высота)] в порядок GeoJSON
+func latLonToPosition(cords []float64) []float64 {
+	return append([]float64{cords[1], cords[0]}, cords[2:]...)
+}
@@ -0,0 +1,479 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CordsNotation описывает формат записи координат, распознанный парсером
+type CordsNotation string
+
+const (
+	// NotationDecimal десятичные градусы: 55.7558 37.6173
+	NotationDecimal CordsNotation = "decimal"
+	// NotationDDM градусы и десятичные минуты: N55 45.348 E37 37.060
+	NotationDDM CordsNotation = "ddm"
+	// NotationDMS градусы, минуты и секунды: 55°45'20.9"N 37°37'03.6"E
+	NotationDMS CordsNotation = "dms"
+)
+
+// DecimalSeparator определяет, каким символом отделяется дробная часть чисел
+type DecimalSeparator string
+
+const (
+	// DecimalAuto определяет разделитель по содержимому ячейки
+	DecimalAuto DecimalSeparator = "auto"
+	// DecimalDot дробная часть отделяется точкой, запятая разделяет координаты
+	DecimalDot DecimalSeparator = "dot"
+	// DecimalComma дробная часть отделяется запятой (55,7558 37,6173)
+	DecimalComma DecimalSeparator = "comma"
+)
+
+// CordsAxis ось координаты, записанной в отдельной ячейке
+type CordsAxis string
+
+const (
+	AxisLatitude  CordsAxis = "latitude"
+	AxisLongitude CordsAxis = "longitude"
+	AxisAltitude  CordsAxis = "altitude"
+)
+
+// CordsParser разбирает строку с координатами в формат [широта, долгота(, высота)]
+type CordsParser interface {
+	Parse(cords string) ([]float64, CordsNotation, error)
+	// ParseAxis разбирает одну координату (широту, долготу или высоту) из отдельной ячейки
+	ParseAxis(value string, axis CordsAxis) (float64, CordsNotation, error)
+}
+
+// DefaultCordsParser распознаёт десятичные градусы, DMS и DDM,
+// буквы полушарий N/S/E/W (в том числе С/Ю/В/З) и знаковые значения
+type DefaultCordsParser struct {
+	// DecimalSeparator разделитель дробной части, по умолчанию DecimalAuto
+	DecimalSeparator DecimalSeparator
+	// LonLat порядок "долгота широта": первая координата без буквы полушария считается долготой
+	LonLat bool
+}
+
+type cordsUnit int
+
+const (
+	unitNone cordsUnit = iota
+	unitDegree
+	unitMinute
+	unitSecond
+)
+
+type cordsTokenKind int
+
+const (
+	tokenNumber cordsTokenKind = iota
+	tokenHemisphere
+	tokenSeparator
+)
+
+type cordsToken struct {
+	kind cordsTokenKind
+	text string
+	unit cordsUnit
+	hemi rune
+}
+
+type cordsComponent struct {
+	values []cordsToken
+	hemi   rune
+}
+
+// Русские обозначения полушарий: с.ш., ю.ш., в.д., з.д.
+var (
+	cyrLatitudeRe  = regexp.MustCompile(`([сю])\.?\s*ш\.?`)
+	cyrLongitudeRe = regexp.MustCompile(`([вз])\.?\s*д\.?`)
+)
+
+// Запятая между цифрами, которая может быть десятичным разделителем
+var decimalCommaRe = regexp.MustCompile(`(\d),(\d)`)
+
+var hemispheres = map[rune]rune{
+	'n': 'N', 's': 'S', 'e': 'E', 'w': 'W',
+	'с': 'N', 'ю': 'S', 'в': 'E', 'з': 'W',
+}
+
+// Parse разбирает строку с координатами
+func (p DefaultCordsParser) Parse(cords string) ([]float64, CordsNotation, error) {
+	normalized := normalizeCords(cords)
+	if p.usesDecimalComma(normalized, false) {
+		normalized = decimalCommaRe.ReplaceAllString(normalized, "$1.$2")
+	}
+
+	tokens, err := tokenizeCords(normalized)
+	if err != nil {
+		return nil, "", err
+	}
+
+	components, err := groupCords(tokens, true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(components) < 2 || len(components) > 3 {
+		return nil, "", fmt.Errorf("ожидается 2 или 3 координаты, получено %d", len(components))
+	}
+
+	values := make([]float64, len(components))
+	notation := NotationDecimal
+	for i, component := range components {
+		value, err := component.value()
+		if err != nil {
+			return nil, "", err
+		}
+		values[i] = value
+
+		if i < 2 {
+			switch len(component.values) {
+			case 2:
+				if notation == NotationDecimal {
+					notation = NotationDDM
+				}
+			case 3:
+				notation = NotationDMS
+			}
+		}
+	}
+
+	ordered, err := orderByHemisphere(components, values, p.LonLat)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return ordered, notation, nil
+}
+
+// ParseAxis разбирает одну координату из отдельной ячейки
+func (p DefaultCordsParser) ParseAxis(value string, axis CordsAxis) (float64, CordsNotation, error) {
+	normalized := normalizeCords(value)
+	if p.usesDecimalComma(normalized, true) {
+		normalized = decimalCommaRe.ReplaceAllString(normalized, "$1.$2")
+	}
+
+	tokens, err := tokenizeCords(normalized)
+	if err != nil {
+		return 0, "", err
+	}
+
+	components, err := groupCords(tokens, false)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(components) != 1 {
+		return 0, "", fmt.Errorf("ожидается одно значение, получено %d", len(components))
+	}
+
+	component := components[0]
+	switch {
+	case component.hemi == 0:
+	case axis == AxisAltitude:
+		return 0, "", fmt.Errorf("высота не может иметь обозначение полушария")
+	case (axis == AxisLatitude) != (component.hemi == 'N' || component.hemi == 'S'):
+		return 0, "", fmt.Errorf("обозначение полушария '%c' не подходит для %s", component.hemi, map[CordsAxis]string{AxisLatitude: "широты", AxisLongitude: "долготы"}[axis])
+	}
+
+	result, err := component.value()
+	if err != nil {
+		return 0, "", err
+	}
+
+	notation := NotationDecimal
+	switch len(component.values) {
+	case 2:
+		notation = NotationDDM
+	case 3:
+		notation = NotationDMS
+	}
+
+	return result, notation, nil
+}
+
+// usesDecimalComma определяет, отделяется ли дробная часть запятой.
+// В режиме auto запятая считается десятичной, если в строке нет точек,
+// запятая стоит между цифрами, а координаты разделены пробелом или точкой с запятой.
+// Для одиночного значения (single) достаточно запятой между цифрами
+func (p DefaultCordsParser) usesDecimalComma(s string, single bool) bool {
+	switch p.DecimalSeparator {
+	case DecimalComma:
+		return true
+	case DecimalDot:
+		return false
+	}
+
+	if strings.Contains(s, ".") || !decimalCommaRe.MatchString(s) {
+		return false
+	}
+	return single || strings.ContainsAny(decimalCommaRe.ReplaceAllString(s, "$1$2"), " \t;")
+}
+
+// normalizeCords приводит типографские символы и русские обозначения к единому виду
+func normalizeCords(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = cyrLatitudeRe.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "с") {
+			return " n "
+		}
+		return " s "
+	})
+	s = cyrLongitudeRe.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "в") {
+			return " e "
+		}
+		return " w "
+	})
+
+	replacer := strings.NewReplacer(
+		"º", "°", "˚", "°",
+		"′′", `"`, "''", `"`, "″", `"`, "“", `"`, "”", `"`,
+		"′", "'", "’", "'", "‘", "'", "`", "'", "´", "'",
+		"−", "-", "–", "-",
+	)
+	return replacer.Replace(s)
+}
+
+// tokenizeCords разбивает строку на числа, обозначения полушарий и разделители
+func tokenizeCords(s string) ([]cordsToken, error) {
+	var tokens []cordsToken
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r == ',' || r == ';':
+			tokens = append(tokens, cordsToken{kind: tokenSeparator})
+		case unicode.IsDigit(r) || r == '.' || ((r == '-' || r == '+') && i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.')):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			i--
+			if strings.Count(text, ".") > 1 {
+				return nil, fmt.Errorf("некорректное число '%s'", text)
+			}
+			tokens = append(tokens, cordsToken{kind: tokenNumber, text: text})
+		case r == '°' || r == '\'' || r == '"':
+			if len(tokens) == 0 || tokens[len(tokens)-1].kind != tokenNumber || tokens[len(tokens)-1].unit != unitNone {
+				return nil, fmt.Errorf("символ '%c' не относится к числу", r)
+			}
+			unit := map[rune]cordsUnit{'°': unitDegree, '\'': unitMinute, '"': unitSecond}[r]
+			tokens[len(tokens)-1].unit = unit
+		default:
+			hemi, ok := hemispheres[r]
+			if !ok {
+				return nil, fmt.Errorf("неожиданный символ '%c'", r)
+			}
+			if isExponent(runes, i) {
+				return nil, fmt.Errorf("экспоненциальная запись числа не поддерживается: '%s'", strings.TrimSpace(s))
+			}
+			tokens = append(tokens, cordsToken{kind: tokenHemisphere, hemi: hemi})
+		}
+	}
+
+	return tokens, nil
+}
+
+// isExponent сообщает, что латинская e в позиции i - показатель степени числа (1e5, 2.5E-3),
+// а не буква полушария: перед ней цифра, после - целое число со знаком или без,
+// за которым не следует дробная часть
+func isExponent(runes []rune, i int) bool {
+	if runes[i] != 'e' && runes[i] != 'E' {
+		return false
+	}
+	if i == 0 || !(unicode.IsDigit(runes[i-1]) || runes[i-1] == '.') {
+		return false
+	}
+	j := i + 1
+	if j < len(runes) && (runes[j] == '-' || runes[j] == '+') {
+		j++
+	}
+	digits := j
+	for j < len(runes) && unicode.IsDigit(runes[j]) {
+		j++
+	}
+	return j > digits && (j == len(runes) || runes[j] != '.')
+}
+
+// groupCords собирает токены в отдельные координаты.
+// split разрешает делить запись без разделителей на несколько координат по количеству чисел
+func groupCords(tokens []cordsToken, split bool) ([]cordsComponent, error) {
+	// Если запись начинается с буквы полушария (N55 45.348), буквы стоят перед числами
+	prefixStyle := false
+	for _, t := range tokens {
+		if t.kind != tokenSeparator {
+			prefixStyle = t.kind == tokenHemisphere
+			break
+		}
+	}
+
+	var components []cordsComponent
+	var cur cordsComponent
+	delimited := false
+
+	flush := func() error {
+		if len(cur.values) == 0 {
+			if cur.hemi != 0 {
+				return fmt.Errorf("обозначение полушария '%c' без значения", cur.hemi)
+			}
+			return nil
+		}
+		components = append(components, cur)
+		cur = cordsComponent{}
+		return nil
+	}
+
+	for _, t := range tokens {
+		switch t.kind {
+		case tokenSeparator:
+			delimited = true
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case tokenHemisphere:
+			delimited = true
+			if prefixStyle {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+				cur.hemi = t.hemi
+				continue
+			}
+			if len(cur.values) == 0 {
+				return nil, fmt.Errorf("обозначение полушария '%c' без значения", t.hemi)
+			}
+			cur.hemi = t.hemi
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case tokenNumber:
+			if t.unit == unitDegree {
+				delimited = true
+				if len(cur.values) > 0 {
+					if err := flush(); err != nil {
+						return nil, err
+					}
+				}
+			}
+			cur.values = append(cur.values, t)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	// Запись без разделителей и обозначений (55 45 20.9 37 37 3.6):
+	// делим числа поровну в зависимости от их количества
+	if split && !delimited && len(components) == 1 {
+		values := components[0].values
+		var size int
+		switch len(values) {
+		case 2, 3:
+			size = 1
+		case 4:
+			size = 2
+		case 6:
+			size = 3
+		default:
+			return nil, fmt.Errorf("не удалось разобрать %d чисел как координаты", len(values))
+		}
+		components = components[:0]
+		for i := 0; i < len(values); i += size {
+			components = append(components, cordsComponent{values: values[i : i+size]})
+		}
+	}
+
+	return components, nil
+}
+
+// value вычисляет значение координаты в десятичных градусах
+func (c cordsComponent) value() (float64, error) {
+	if len(c.values) > 3 {
+		return 0, fmt.Errorf("слишком много чисел в одной координате")
+	}
+
+	parts := make([]float64, len(c.values))
+	for i, t := range c.values {
+		if t.unit != unitNone && int(t.unit) != i+1 {
+			return 0, fmt.Errorf("нарушен порядок градусов, минут и секунд в '%s'", t.text)
+		}
+		if i > 0 && strings.ContainsAny(t.text, "+-") {
+			return 0, fmt.Errorf("минуты и секунды не могут иметь знак: '%s'", t.text)
+		}
+		val, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return 0, err
+		}
+		parts[i] = val
+	}
+
+	negative := strings.HasPrefix(c.values[0].text, "-")
+	deg := math.Abs(parts[0])
+	if len(parts) > 1 {
+		if deg != math.Trunc(deg) {
+			return 0, fmt.Errorf("градусы должны быть целыми при указании минут: '%s'", c.values[0].text)
+		}
+		if deg > 180 {
+			return 0, fmt.Errorf("градусы вне диапазона: '%s'", c.values[0].text)
+		}
+	}
+	for i, part := range parts[1:] {
+		if part >= 60 {
+			return 0, fmt.Errorf("значение '%s' должно быть меньше 60", c.values[i+1].text)
+		}
+		if i == 0 && len(parts) == 3 && part != math.Trunc(part) {
+			return 0, fmt.Errorf("минуты должны быть целыми при указании секунд: '%s'", c.values[i+1].text)
+		}
+	}
+
+	value := deg
+	if len(parts) > 1 {
+		value += parts[1] / 60
+	}
+	if len(parts) > 2 {
+		value += parts[2] / 3600
+	}
+
+	if c.hemi == 'S' || c.hemi == 'W' {
+		if negative {
+			return 0, fmt.Errorf("противоречивый знак и полушарие '%c'", c.hemi)
+		}
+		negative = true
+	}
+	if negative {
+		value = -value
+	}
+
+	return value, nil
+}
+
+// orderByHemisphere расставляет координаты в порядке [широта, долгота] по буквам полушарий,
+// а без букв - по заданному порядку (lonLat - сначала долгота)
+func orderByHemisphere(components []cordsComponent, values []float64, lonLat bool) ([]float64, error) {
+	isLat := func(h rune) bool { return h == 'N' || h == 'S' }
+	isLon := func(h rune) bool { return h == 'E' || h == 'W' }
+
+	first, second := components[0].hemi, components[1].hemi
+	if len(components) == 3 && components[2].hemi != 0 {
+		return nil, fmt.Errorf("высота не может иметь обозначение полушария")
+	}
+
+	if first != 0 && second != 0 && isLat(first) == isLat(second) {
+		return nil, fmt.Errorf("обе координаты относятся к одной оси")
+	}
+	if isLon(first) || isLat(second) || (lonLat && first == 0 && second == 0) {
+		values[0], values[1] = values[1], values[0]
+	}
+
+	return values, nil
+}
@@ -0,0 +1,292 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Geometry геометрия объекта одного из типов GeoJSON (RFC 7946).
+// Координаты всех геометрий хранятся в порядке GeoJSON: [долгота, широта(, высота)].
+// Геометрия без координат (POINT EMPTY и т.п.) считается пустой
+type Geometry interface {
+	// GeometryType возвращает тип геометрии
+	GeometryType() CordsDataType
+	// IsEmpty сообщает, что геометрия не содержит координат
+	IsEmpty() bool
+	// HasZ сообщает, что хотя бы одна координата содержит высоту
+	HasZ() bool
+	// Validate проверяет размерность координат и структуру геометрии
+	Validate() error
+}
+
+// PointGeometry точка
+type PointGeometry struct {
+	Coordinates []float64
+}
+
+// LineStringGeometry линия из двух и более точек
+type LineStringGeometry struct {
+	Coordinates [][]float64
+}
+
+// PolygonGeometry полигон: первый контур внешний, остальные - отверстия
+type PolygonGeometry struct {
+	Coordinates [][][]float64
+}
+
+// MultiPointGeometry набор точек
+type MultiPointGeometry struct {
+	Coordinates [][]float64
+}
+
+// MultiLineStringGeometry набор линий
+type MultiLineStringGeometry struct {
+	Coordinates [][][]float64
+}
+
+// MultiPolygonGeometry набор полигонов
+type MultiPolygonGeometry struct {
+	Coordinates [][][][]float64
+}
+
+// CollectionGeometry коллекция геометрий разных типов
+type CollectionGeometry struct {
+	Geometries []Geometry
+}
+
+// NewPointGeometry создаёт точку по долготе, широте и необязательной высоте
+func NewPointGeometry(lon, lat float64, alt ...float64) *PointGeometry {
+	return &PointGeometry{Coordinates: append([]float64{lon, lat}, alt...)}
+}
+
+// NewLineStringGeometry создаёт линию из координат [долгота, широта(, высота)]
+func NewLineStringGeometry(positions ...[]float64) *LineStringGeometry {
+	return &LineStringGeometry{Coordinates: positions}
+}
+
+// NewPolygonGeometry создаёт полигон из внешнего контура и отверстий
+func NewPolygonGeometry(rings ...[][]float64) *PolygonGeometry {
+	return &PolygonGeometry{Coordinates: rings}
+}
+
+// NewMultiPointGeometry создаёт мультиточку
+func NewMultiPointGeometry(points ...[]float64) *MultiPointGeometry {
+	return &MultiPointGeometry{Coordinates: points}
+}
+
+// NewMultiLineStringGeometry создаёт мультилинию
+func NewMultiLineStringGeometry(lines ...[][]float64) *MultiLineStringGeometry {
+	return &MultiLineStringGeometry{Coordinates: lines}
+}
+
+// NewMultiPolygonGeometry создаёт мультиполигон
+func NewMultiPolygonGeometry(polygons ...[][][]float64) *MultiPolygonGeometry {
+	return &MultiPolygonGeometry{Coordinates: polygons}
+}
+
+// NewCollectionGeometry создаёт коллекцию геометрий
+func NewCollectionGeometry(geometries ...Geometry) *CollectionGeometry {
+	return &CollectionGeometry{Geometries: geometries}
+}
+
+// NewEmptyGeometry создаёт пустую геометрию заданного типа
+func NewEmptyGeometry(typ CordsDataType) (Geometry, error) {
+	switch typ {
+	case Point:
+		return &PointGeometry{}, nil
+	case LineString:
+		return &LineStringGeometry{}, nil
+	case Polygon:
+		return &PolygonGeometry{}, nil
+	case MultiPoint:
+		return &MultiPointGeometry{}, nil
+	case MultiLineString:
+		return &MultiLineStringGeometry{}, nil
+	case MultiPolygon:
+		return &MultiPolygonGeometry{}, nil
+	case GeometryCollection:
+		return &CollectionGeometry{}, nil
+	}
+	return nil, fmt.Errorf("неподдерживаемый тип геометрии '%s'", typ)
+}
+
+func (g *PointGeometry) GeometryType() CordsDataType           { return Point }
+func (g *LineStringGeometry) GeometryType() CordsDataType      { return LineString }
+func (g *PolygonGeometry) GeometryType() CordsDataType         { return Polygon }
+func (g *MultiPointGeometry) GeometryType() CordsDataType      { return MultiPoint }
+func (g *MultiLineStringGeometry) GeometryType() CordsDataType { return MultiLineString }
+func (g *MultiPolygonGeometry) GeometryType() CordsDataType    { return MultiPolygon }
+func (g *CollectionGeometry) GeometryType() CordsDataType      { return GeometryCollection }
+
+func (g *PointGeometry) IsEmpty() bool           { return len(g.Coordinates) == 0 }
+func (g *LineStringGeometry) IsEmpty() bool      { return len(g.Coordinates) == 0 }
+func (g *PolygonGeometry) IsEmpty() bool         { return len(g.Coordinates) == 0 }
+func (g *MultiPointGeometry) IsEmpty() bool      { return len(g.Coordinates) == 0 }
+func (g *MultiLineStringGeometry) IsEmpty() bool { return len(g.Coordinates) == 0 }
+func (g *MultiPolygonGeometry) IsEmpty() bool    { return len(g.Coordinates) == 0 }
+func (g *CollectionGeometry) IsEmpty() bool      { return len(g.Geometries) == 0 }
+
+func (g *PointGeometry) HasZ() bool { return len(g.Coordinates) > 2 }
+
+func (g *LineStringGeometry) HasZ() bool { return positionsHaveZ(g.Coordinates) }
+
+func (g *PolygonGeometry) HasZ() bool { return ringsHaveZ(g.Coordinates) }
+
+func (g *MultiPointGeometry) HasZ() bool { return positionsHaveZ(g.Coordinates) }
+
+func (g *MultiLineStringGeometry) HasZ() bool { return ringsHaveZ(g.Coordinates) }
+
+func (g *MultiPolygonGeometry) HasZ() bool {
+	for _, polygon := range g.Coordinates {
+		if ringsHaveZ(polygon) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *CollectionGeometry) HasZ() bool {
+	for _, member := range g.Geometries {
+		if member != nil && member.HasZ() {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *PointGeometry) Validate() error {
+	if g.IsEmpty() {
+		return nil
+	}
+	return validatePosition(g.Coordinates)
+}
+
+func (g *LineStringGeometry) Validate() error {
+	if g.IsEmpty() {
+		return nil
+	}
+	return validateLine(g.Coordinates)
+}
+
+func (g *PolygonGeometry) Validate() error {
+	if g.IsEmpty() {
+		return nil
+	}
+	return validateRings(g.Coordinates)
+}
+
+func (g *MultiPointGeometry) Validate() error {
+	for i, pos := range g.Coordinates {
+		if err := validatePosition(pos); err != nil {
+			return fmt.Errorf("точка %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (g *MultiLineStringGeometry) Validate() error {
+	for i, line := range g.Coordinates {
+		if err := validateLine(line); err != nil {
+			return fmt.Errorf("линия %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (g *MultiPolygonGeometry) Validate() error {
+	for i, polygon := range g.Coordinates {
+		if err := validateRings(polygon); err != nil {
+			return fmt.Errorf("полигон %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (g *CollectionGeometry) Validate() error {
+	for i, member := range g.Geometries {
+		if member == nil {
+			return fmt.Errorf("геометрия %d коллекции не задана", i+1)
+		}
+		if err := member.Validate(); err != nil {
+			return fmt.Errorf("%s %d коллекции: %w", member.GeometryType(), i+1, err)
+		}
+	}
+	return nil
+}
+
+// validatePosition проверяет, что координата содержит 2 или 3 конечных числа
+func validatePosition(pos []float64) error {
+	if len(pos) != 2 && len(pos) != 3 {
+		return fmt.Errorf("ожидается 2 или 3 координаты, получено %d", len(pos))
+	}
+	for _, v := range pos {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("некорректное значение координаты %v", v)
+		}
+	}
+	return nil
+}
+
+// validateLine проверяет, что линия состоит хотя бы из двух корректных точек
+func validateLine(line [][]float64) error {
+	if len(line) < 2 {
+		return fmt.Errorf("линия должна содержать хотя бы 2 точки, получено %d", len(line))
+	}
+	for i, pos := range line {
+		if err := validatePosition(pos); err != nil {
+			return fmt.Errorf("точка %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validateRings проверяет контуры полигона: не меньше 4 точек, первая совпадает с последней
+func validateRings(rings [][][]float64) error {
+	if len(rings) == 0 {
+		return fmt.Errorf("полигон не содержит контуров")
+	}
+	for i, ring := range rings {
+		if len(ring) < 4 {
+			return fmt.Errorf("контур %d должен содержать хотя бы 4 точки, получено %d", i+1, len(ring))
+		}
+		for j, pos := range ring {
+			if err := validatePosition(pos); err != nil {
+				return fmt.Errorf("контур %d, точка %d: %w", i+1, j+1, err)
+			}
+		}
+		if !samePosition(ring[0], ring[len(ring)-1]) {
+			return fmt.Errorf("контур %d не замкнут: первая и последняя точки различаются", i+1)
+		}
+	}
+	return nil
+}
+
+func samePosition(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func positionsHaveZ(positions [][]float64) bool {
+	for _, pos := range positions {
+		if len(pos) > 2 {
+			return true
+		}
+	}
+	return false
+}
+
+func ringsHaveZ(rings [][][]float64) bool {
+	for _, ring := range rings {
+		if positionsHaveZ(ring) {
+			return true
+		}
+	}
+	return false
+}
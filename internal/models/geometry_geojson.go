@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// GeometryFromGeoJSON преобразует геометрию paulmach/go.geojson в Geometry.
+// Для geometry: null возвращается nil
+func GeometryFromGeoJSON(g *geojson.Geometry) (Geometry, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	switch g.Type {
+	case geojson.GeometryPoint:
+		return &PointGeometry{Coordinates: g.Point}, nil
+	case geojson.GeometryLineString:
+		return &LineStringGeometry{Coordinates: g.LineString}, nil
+	case geojson.GeometryPolygon:
+		return &PolygonGeometry{Coordinates: g.Polygon}, nil
+	case geojson.GeometryMultiPoint:
+		return &MultiPointGeometry{Coordinates: g.MultiPoint}, nil
+	case geojson.GeometryMultiLineString:
+		return &MultiLineStringGeometry{Coordinates: g.MultiLineString}, nil
+	case geojson.GeometryMultiPolygon:
+		return &MultiPolygonGeometry{Coordinates: g.MultiPolygon}, nil
+	case geojson.GeometryCollection:
+		collection := &CollectionGeometry{}
+		for _, member := range g.Geometries {
+			if member == nil {
+				continue
+			}
+			geometry, err := GeometryFromGeoJSON(member)
+			if err != nil {
+				return nil, err
+			}
+			collection.Geometries = append(collection.Geometries, geometry)
+		}
+		return collection, nil
+	}
+	return nil, fmt.Errorf("неподдерживаемый тип геометрии '%s'", g.Type)
+}
+
+// GeometryToGeoJSON преобразует Geometry в геометрию paulmach/go.geojson.
+// Отсутствующая и пустая геометрия записывается как geometry: null
+func GeometryToGeoJSON(g Geometry) *geojson.Geometry {
+	if g == nil || g.IsEmpty() {
+		return nil
+	}
+
+	switch v := g.(type) {
+	case *PointGeometry:
+		return geojson.NewPointGeometry(v.Coordinates)
+	case *LineStringGeometry:
+		return geojson.NewLineStringGeometry(v.Coordinates)
+	case *PolygonGeometry:
+		return geojson.NewPolygonGeometry(v.Coordinates)
+	case *MultiPointGeometry:
+		return geojson.NewMultiPointGeometry(v.Coordinates...)
+	case *MultiLineStringGeometry:
+		return geojson.NewMultiLineStringGeometry(v.Coordinates...)
+	case *MultiPolygonGeometry:
+		return geojson.NewMultiPolygonGeometry(v.Coordinates...)
+	case *CollectionGeometry:
+		geometries := make([]*geojson.Geometry, 0, len(v.Geometries))
+		for _, member := range v.Geometries {
+			if geometry := GeometryToGeoJSON(member); geometry != nil {
+				geometries = append(geometries, geometry)
+			}
+		}
+		return geojson.NewCollectionGeometry(geometries...)
+	}
+	return nil
+}
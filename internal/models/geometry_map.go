@@ -0,0 +1,56 @@
+package models
+
+// MapPositions возвращает копию геометрии, в которой каждая позиция заменена результатом fn.
+// Исходная геометрия не изменяется
+func MapPositions(g Geometry, fn func([]float64) []float64) Geometry {
+	switch v := g.(type) {
+	case *PointGeometry:
+		if v.IsEmpty() {
+			return &PointGeometry{}
+		}
+		return &PointGeometry{Coordinates: fn(v.Coordinates)}
+	case *LineStringGeometry:
+		return &LineStringGeometry{Coordinates: mapLine(v.Coordinates, fn)}
+	case *PolygonGeometry:
+		return &PolygonGeometry{Coordinates: mapRings(v.Coordinates, fn)}
+	case *MultiPointGeometry:
+		return &MultiPointGeometry{Coordinates: mapLine(v.Coordinates, fn)}
+	case *MultiLineStringGeometry:
+		return &MultiLineStringGeometry{Coordinates: mapRings(v.Coordinates, fn)}
+	case *MultiPolygonGeometry:
+		polygons := make([][][][]float64, len(v.Coordinates))
+		for i, polygon := range v.Coordinates {
+			polygons[i] = mapRings(polygon, fn)
+		}
+		return &MultiPolygonGeometry{Coordinates: polygons}
+	case *CollectionGeometry:
+		members := make([]Geometry, len(v.Geometries))
+		for i, member := range v.Geometries {
+			members[i] = MapPositions(member, fn)
+		}
+		return &CollectionGeometry{Geometries: members}
+	}
+	return g
+}
+
+func mapLine(line [][]float64, fn func([]float64) []float64) [][]float64 {
+	if line == nil {
+		return nil
+	}
+	result := make([][]float64, len(line))
+	for i, pos := range line {
+		result[i] = fn(pos)
+	}
+	return result
+}
+
+func mapRings(rings [][][]float64, fn func([]float64) []float64) [][][]float64 {
+	if rings == nil {
+		return nil
+	}
+	result := make([][][]float64, len(rings))
+	for i, ring := range rings {
+		result[i] = mapLine(ring, fn)
+	}
+	return result
+}
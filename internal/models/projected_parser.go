@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ProjectedCordsParser разбирает прямоугольные координаты в метрах (Гаусс-Крюгер, UTM и т.д.).
+// Запись следует геодезической традиции "X Y": сначала северная координата, затем восточная,
+// поэтому на место широты попадает X (север), на место долготы - Y (восток).
+// Обозначения градусов и полушарий не допускаются
+type ProjectedCordsParser struct {
+	// DecimalSeparator разделитель дробной части, по умолчанию DecimalAuto
+	DecimalSeparator DecimalSeparator
+}
+
+// Parse разбирает строку "X Y" или "X Y H" в [север, восток(, высота)]
+func (p ProjectedCordsParser) Parse(cords string) ([]float64, CordsNotation, error) {
+	normalized := normalizeCords(cords)
+	comma := p.usesDecimalComma(normalized, false)
+
+	fields := strings.FieldsFunc(normalized, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ';' || (r == ',' && !comma)
+	})
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, "", fmt.Errorf("ожидается 2 или 3 прямоугольные координаты, получено %d", len(fields))
+	}
+
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := parseMetres(field, comma)
+		if err != nil {
+			return nil, "", err
+		}
+		values[i] = value
+	}
+	return values, NotationDecimal, nil
+}
+
+// ParseAxis разбирает одну прямоугольную координату; пробелы между разрядами допускаются (6 182 341,07)
+func (p ProjectedCordsParser) ParseAxis(value string, _ CordsAxis) (float64, CordsNotation, error) {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, normalizeCords(value))
+
+	result, err := parseMetres(normalized, p.usesDecimalComma(normalized, true))
+	if err != nil {
+		return 0, "", err
+	}
+	return result, NotationDecimal, nil
+}
+
+// usesDecimalComma определяет разделитель дробной части так же, как DefaultCordsParser
+func (p ProjectedCordsParser) usesDecimalComma(s string, single bool) bool {
+	return DefaultCordsParser{DecimalSeparator: p.DecimalSeparator}.usesDecimalComma(s, single)
+}
+
+// parseMetres разбирает число; comma - дробная часть отделена запятой
+func parseMetres(s string, comma bool) (float64, error) {
+	text := s
+	if comma {
+		text = strings.Replace(text, ",", ".", 1)
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("некорректное значение прямоугольной координаты '%s'", s)
+	}
+	return value, nil
+}
@@ -0,0 +1,12 @@
+package models
+
+// Свойства, в которых форматы со своей структурой (например, KML) передают данные,
+// для которых в CordsData нет отдельного поля
+const (
+	// PropertyFolder путь папки, в которой лежит объект, через "/" ("Маршруты/2024")
+	PropertyFolder = "folder"
+	// PropertyTime время объекта в формате RFC 3339
+	PropertyTime = "time"
+	// PropertyCoordTimes время каждой точки линии (массив строк RFC 3339)
+	PropertyCoordTimes = "coordTimes"
+)
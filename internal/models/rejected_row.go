@@ -0,0 +1,36 @@
+package models
+
+// RejectReason код причины, по которой строка источника не попала в результат
+type RejectReason string
+
+const (
+	// RejectCoordinates не удалось разобрать координаты
+	RejectCoordinates RejectReason = "coordinates"
+	// RejectIncomplete не заполнена широта или долгота
+	RejectIncomplete RejectReason = "incomplete"
+	// RejectWKT не удалось разобрать WKT
+	RejectWKT RejectReason = "wkt"
+	// RejectCRS координаты не подходят к системе координат таблицы
+	RejectCRS RejectReason = "crs"
+	// RejectGroup не заполнен ключ группы линии или полигона
+	RejectGroup RejectReason = "group"
+	// RejectGeometry из строк группы не удалось собрать линию или полигон, или геометрия недопустима
+	RejectGeometry RejectReason = "geometry"
+	// RejectRange широта вне ±90 или долгота вне ±180
+	RejectRange RejectReason = "range"
+	// RejectSwapped перепутаны широта и долгота (excel.swapped: skip)
+	RejectSwapped RejectReason = "swapped"
+)
+
+// RejectedRow строка источника, которая не попала в результат
+type RejectedRow struct {
+	// Row номер строки листа (с 1)
+	Row int
+	// Column номер столбца с ошибкой (A=1), 0 - ошибка относится ко всей строке
+	Column int
+	// Values исходные значения ячеек строки
+	Values []string
+	Reason RejectReason
+	// Message описание ошибки
+	Message string
+}
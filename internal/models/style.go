@@ -0,0 +1,49 @@
+package models
+
+// Ключи оформления объектов по simplestyle-spec и Яндекс Конструктору карт
+const (
+	StyleMarkerColor   = "marker-color"
+	StyleMarkerSymbol  = "marker-symbol"
+	StyleMarkerSize    = "marker-size"
+	StyleIconContent   = "iconContent"
+	StylePreset        = "preset"
+	StyleStroke        = "stroke"
+	StyleStrokeWidth   = "stroke-width"
+	StyleStrokeOpacity = "stroke-opacity"
+	StyleFill          = "fill"
+	StyleFillOpacity   = "fill-opacity"
+)
+
+// StyleKeys все поддерживаемые ключи оформления в порядке вывода
+var StyleKeys = []string{
+	StyleMarkerColor, StyleMarkerSymbol, StyleMarkerSize, StyleIconContent, StylePreset,
+	StyleStroke, StyleStrokeWidth, StyleStrokeOpacity, StyleFill, StyleFillOpacity,
+}
+
+// Style оформление объекта: ключ свойства GeoJSON -> значение
+type Style map[string]any
+
+// Set устанавливает параметр оформления
+func (s *Style) Set(key string, value any) {
+	if *s == nil {
+		*s = make(Style)
+	}
+	(*s)[key] = value
+}
+
+// StyleAppliesTo сообщает, относится ли параметр оформления к геометрии данного типа:
+// marker-*, iconContent и preset - к точкам, stroke-* - к линиям и полигонам, fill-* - к полигонам
+func StyleAppliesTo(key, geometryType string) bool {
+	isPoint := geometryType == "Point" || geometryType == "MultiPoint"
+	isPolygon := geometryType == "Polygon" || geometryType == "MultiPolygon"
+
+	switch key {
+	case StyleMarkerColor, StyleMarkerSymbol, StyleMarkerSize, StyleIconContent, StylePreset:
+		return isPoint
+	case StyleFill, StyleFillOpacity:
+		return isPolygon
+	case StyleStroke, StyleStrokeWidth, StyleStrokeOpacity:
+		return !isPoint
+	}
+	return true
+}
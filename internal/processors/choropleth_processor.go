@@ -0,0 +1,171 @@
+package processors
+
+import (
+	"fmt"
+
+	geojson "github.com/paulmach/go.geojson"
+	"github.com/rmay1er/jgeo-excel/internal/classify"
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/readers"
+	gjs "github.com/rmay1er/jgeo-excel/internal/writers/geojson"
+)
+
+// ChoroplethProcessor присоединяет строки таблицы к полигонам по ключу
+// и раскрашивает полигоны по числовому показателю
+type ChoroplethProcessor struct {
+	reader readers.TableReader
+	writer *gjs.GeojsonWriter
+	cfg    config.ChoroplethConfig
+}
+
+// NewChoroplethProcessor создает процессор картограммы
+func NewChoroplethProcessor(reader readers.TableReader, writer *gjs.GeojsonWriter, cfg config.ChoroplethConfig) *ChoroplethProcessor {
+	return &ChoroplethProcessor{
+		reader: reader,
+		writer: writer,
+		cfg:    cfg,
+	}
+}
+
+// Process читает таблицу, переносит свойства строк в полигоны с тем же ключом и задаёт заливку
+func (p *ChoroplethProcessor) Process() error {
+	fmt.Println("📖 Читаю таблицу показателей...")
+	data, err := p.reader.ReadTable()
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении данных: %w", err)
+	}
+	fmt.Printf("✅ Прочитано строк: %d\n", len(*data))
+
+	rows := make(map[string]models.CordsData, len(*data))
+	for _, row := range *data {
+		if _, ok := rows[row.ID]; ok {
+			fmt.Printf("⚠️  Ключ '%s' повторяется в таблице, используется последняя строка\n", row.ID)
+		}
+		rows[row.ID] = row
+	}
+
+	polygons := p.writer.Features(isPolygonFeature)
+	if len(polygons) == 0 {
+		return fmt.Errorf("в базовом файле нет полигонов")
+	}
+
+	// Классы строятся по показателям полигонов, которые есть в таблице
+	matched := make(map[*geojson.Feature]models.CordsData)
+	joined := make(map[string]bool)
+	var values []float64
+	for _, feature := range polygons {
+		key := gjs.FeatureKey(feature, p.cfg.JoinOn)
+		row, ok := rows[key]
+		if key == "" || !ok {
+			continue
+		}
+		matched[feature] = row
+		joined[key] = true
+		if value, ok := row.Properties[p.cfg.Value].(float64); ok {
+			values = append(values, value)
+		}
+	}
+	for _, row := range *data {
+		if !joined[row.ID] {
+			fmt.Printf("⚠️  Для строки с ключом '%s' не найден полигон (%s)\n", row.ID, p.cfg.JoinOn)
+		}
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("у присоединённых полигонов нет числовых значений показателя '%s'", p.cfg.Value)
+	}
+
+	breaks, err := classify.Breaks(p.cfg.Method, values, p.cfg.Classes, p.cfg.Breaks)
+	if err != nil {
+		return err
+	}
+	palette, err := classify.Palette(p.cfg.Palette, p.cfg.Classes)
+	if err != nil {
+		return err
+	}
+	opacities := classify.Ramp(p.cfg.FillOpacity, p.cfg.FillOpacity, p.cfg.Classes)
+	if len(p.cfg.OpacityRange) == 2 {
+		opacities = classify.Ramp(p.cfg.OpacityRange[0], p.cfg.OpacityRange[1], p.cfg.Classes)
+	}
+
+	counts := make([]int, p.cfg.Classes)
+	noData := 0
+	for _, feature := range polygons {
+		row, ok := matched[feature]
+		if ok {
+			copyRow(feature, row)
+		}
+
+		value, hasValue := row.Properties[p.cfg.Value].(float64)
+		if !ok || !hasValue {
+			noData++
+			if p.cfg.NoDataColor != "" {
+				feature.SetProperty(models.StyleFill, p.cfg.NoDataColor)
+			}
+			continue
+		}
+
+		class := classify.ClassOf(value, breaks)
+		counts[class]++
+		feature.SetProperty(models.StyleFill, palette[class])
+		feature.SetProperty(models.StyleFillOpacity, opacities[class])
+	}
+
+	fmt.Printf("🗺️  Полигонов: %d, присоединено: %d, без данных: %d\n", len(polygons), len(matched), noData)
+	printLegend(breaks, palette, counts)
+	return nil
+}
+
+// copyRow переносит название, описание и свойства строки в полигон
+func copyRow(feature *geojson.Feature, row models.CordsData) {
+	for key, value := range row.Properties {
+		feature.SetProperty(key, value)
+	}
+	if row.IconCaption != "" {
+		feature.SetProperty("iconCaption", row.IconCaption)
+	}
+	if row.Description != "" {
+		feature.SetProperty("description", row.Description)
+	}
+}
+
+// printLegend выводит границы классов, их цвета и количество полигонов
+func printLegend(breaks []float64, palette []string, counts []int) {
+	fmt.Println("🎨 Классы:")
+	for i, color := range palette {
+		var rng string
+		switch {
+		case len(breaks) == 0:
+			rng = "все значения"
+		case i == 0:
+			rng = fmt.Sprintf("≤ %.6g", breaks[0])
+		case i == len(breaks):
+			rng = fmt.Sprintf("> %.6g", breaks[i-1])
+		default:
+			rng = fmt.Sprintf("%.6g – %.6g", breaks[i-1], breaks[i])
+		}
+		fmt.Printf("  %d. %s: %s (%d)\n", i+1, rng, color, counts[i])
+	}
+}
+
+// isPolygonFeature отбирает полигоны и мультиполигоны
+func isPolygonFeature(feature *geojson.Feature) bool {
+	if feature.Geometry == nil {
+		return false
+	}
+	return feature.Geometry.Type == geojson.GeometryPolygon || feature.Geometry.Type == geojson.GeometryMultiPolygon
+}
+
+// Close закрывает Reader и Writer
+func (p *ChoroplethProcessor) Close() error {
+	var firstErr error
+	if p.reader != nil {
+		firstErr = p.reader.Close()
+	}
+	if p.writer != nil {
+		if err := p.writer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
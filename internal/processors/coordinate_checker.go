@@ -0,0 +1,135 @@
+package processors
+
+import (
+	"fmt"
+
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/spatial"
+)
+
+// CoordinateCheckProperty свойство, которым отмечаются подозрительные строки (excel.swapped: flag)
+const CoordinateCheckProperty = "coordinate_check"
+
+// Значения свойства CoordinateCheckProperty
+const (
+	CheckSwapped = "перепутаны широта и долгота?"
+	CheckOutside = "вне области данных"
+)
+
+// CoordinateChecker проверяет координаты прочитанных объектов: строки с широтой вне ±90
+// или долготой вне ±180 пропускаются, а в режиме auto строки с перепутанными широтой и долготой
+// исправляются, отмечаются или пропускаются.
+//
+// Порядок считается перепутанным, если объект не попадает в область данных, а после перестановки
+// попадает. Без области перепутанными считаются только строки, которые становятся допустимыми
+// после перестановки (например, 37.6 155.7)
+type CoordinateChecker struct {
+	order   string
+	swapped string
+	region  *spatial.BBox
+	// Rejected объекты, пропущенные последним вызовом Apply
+	Rejected []models.RejectedRow
+}
+
+// NewCoordinateChecker создаёт этап проверки координат; region - ожидаемая область данных или nil
+func NewCoordinateChecker(order, swapped string, region *spatial.BBox) *CoordinateChecker {
+	return &CoordinateChecker{order: order, swapped: swapped, region: region}
+}
+
+// Apply проверяет координаты объектов. Объекты без геометрии не изменяются
+func (c *CoordinateChecker) Apply(data []models.CordsData) ([]models.CordsData, error) {
+	c.Rejected = nil
+	result := make([]models.CordsData, 0, len(data))
+	var invalid, swapped, outside int
+
+	for i, item := range data {
+		if item.Geometry == nil || item.Geometry.IsEmpty() {
+			result = append(result, item)
+			continue
+		}
+
+		label := fmt.Sprintf("объект %d", i+1)
+		if item.Row > 0 {
+			label = fmt.Sprintf("строка %d", item.Row)
+		}
+		if item.IconCaption != "" {
+			label += fmt.Sprintf(" «%s»", item.IconCaption)
+		}
+		flipped := models.MapPositions(item.Geometry, swapPosition)
+		valid := allPositions(item.Geometry, spatial.ValidPosition)
+
+		switch {
+		case c.order == config.OrderAuto && c.isSwapped(item.Geometry, flipped, valid):
+			swapped++
+			switch c.swapped {
+			case config.SwappedFix:
+				item.Geometry = flipped
+				fmt.Printf("🔄 %s: широта и долгота переставлены\n", label)
+			case config.SwappedFlag:
+				item.SetProperty(CoordinateCheckProperty, CheckSwapped)
+				fmt.Printf("⚠️  %s: похоже, перепутаны широта и долгота, объект отмечен свойством %s\n", label, CoordinateCheckProperty)
+			case config.SwappedSkip:
+				fmt.Printf("⚠️  Пропущено (%s): похоже, перепутаны широта и долгота\n", label)
+				c.reject(item, models.RejectSwapped, "похоже, перепутаны широта и долгота")
+				continue
+			}
+		case !valid:
+			invalid++
+			hint := ""
+			if allPositions(flipped, spatial.ValidPosition) {
+				hint = ": похоже, порядок «долгота широта» (excel.coordinate_order)"
+			}
+			fmt.Printf("⚠️  Пропущено (%s): широта вне ±90 или долгота вне ±180%s\n", label, hint)
+			c.reject(item, models.RejectRange, "широта вне ±90 или долгота вне ±180"+hint)
+			continue
+		case c.region != nil && !allPositions(item.Geometry, c.region.Contains):
+			outside++
+			fmt.Printf("⚠️  %s: координаты вне области данных\n", label)
+			if c.order == config.OrderAuto && c.swapped == config.SwappedFlag {
+				item.SetProperty(CoordinateCheckProperty, CheckOutside)
+			}
+		}
+		result = append(result, item)
+	}
+
+	if invalid+swapped+outside > 0 {
+		fmt.Printf("🧪 Проверка координат: недопустимых %d, с перепутанным порядком %d, вне области данных %d\n", invalid, swapped, outside)
+	}
+	if c.order == config.OrderAuto && swapped > len(data)/2 {
+		fmt.Printf("💡 Большинство объектов (%d из %d) записаны в порядке «долгота широта»: укажите excel.coordinate_order: lonlat\n", swapped, len(data))
+	}
+	return result, nil
+}
+
+// reject запоминает пропущенный объект: по одной записи на каждую строку источника
+func (c *CoordinateChecker) reject(item models.CordsData, reason models.RejectReason, message string) {
+	for _, row := range item.SourceRows() {
+		c.Rejected = append(c.Rejected, models.RejectedRow{Row: row, Reason: reason, Message: message})
+	}
+}
+
+// isSwapped определяет, выглядит ли геометрия как геометрия с перепутанными широтой и долготой
+func (c *CoordinateChecker) isSwapped(geometry, flipped models.Geometry, valid bool) bool {
+	if c.region != nil {
+		return !allPositions(geometry, c.region.Contains) && allPositions(flipped, c.region.Contains)
+	}
+	return !valid && allPositions(flipped, spatial.ValidPosition)
+}
+
+// swapPosition меняет местами долготу и широту, высота сохраняется
+func swapPosition(pos []float64) []float64 {
+	return append([]float64{pos[1], pos[0]}, pos[2:]...)
+}
+
+// allPositions сообщает, что все позиции геометрии удовлетворяют условию
+func allPositions(geometry models.Geometry, ok func([]float64) bool) bool {
+	result := true
+	models.MapPositions(geometry, func(pos []float64) []float64 {
+		if result && !ok(pos) {
+			result = false
+		}
+		return pos
+	})
+	return result
+}
@@ -3,6 +3,7 @@ package processors
 import (
 	"fmt"
 
+	"github.com/rmay1er/jgeo-excel/internal/models"
 	"github.com/rmay1er/jgeo-excel/internal/readers"
 	"github.com/rmay1er/jgeo-excel/internal/writers"
 )
@@ -11,6 +12,9 @@ import (
 type MarksProcessor struct {
 	reader readers.Reader
 	writer writers.Writer
+	stages []Stage
+	// result данные, переданные в Writer последним вызовом Process
+	result []models.CordsData
 }
 
 // NewMarkCoordinatesProcessor создает новый процессор координат
@@ -21,8 +25,15 @@ func NewMarksProcessor(reader readers.Reader, writer writers.Writer) *MarksProce
 	}
 }
 
+// AddStage добавляет этап обработки между чтением и записью
+func (p *MarksProcessor) AddStage(stage Stage) {
+	p.stages = append(p.stages, stage)
+}
+
 // Process выполняет основной процесс: читает данные из Reader, пишет в Writer
 func (p *MarksProcessor) Process(color ...string) error {
+	p.result = nil
+
 	// 1. Читаем данные из Reader
 	fmt.Println("📖 Читаю данные из источника...")
 	data, err := p.reader.Read()
@@ -31,10 +42,22 @@ func (p *MarksProcessor) Process(color ...string) error {
 	}
 	fmt.Printf("✅ Прочитано %d координат\n", len(*data))
 
+	// Этапы обработки выполняются по порядку добавления
+	for _, stage := range p.stages {
+		result, err := stage.Apply(*data)
+		if err != nil {
+			return fmt.Errorf("ошибка при обработке данных: %w", err)
+		}
+		data = &result
+	}
+
+	p.result = *data
+
 	// 2. Пишем данные через Writer
 	fmt.Println("✍️  Записываю данные в целевой формат...")
+	// Если цвет не передан, используется цвет по умолчанию; пустая строка отключает цвет
 	var defaultColor string = "#ed4543"
-	if len(color) > 0 && color[0] != "" {
+	if len(color) > 0 {
 		defaultColor = color[0]
 	}
 	if err := p.writer.Write(data, defaultColor); err != nil {
@@ -45,6 +68,11 @@ func (p *MarksProcessor) Process(color ...string) error {
 	return nil
 }
 
+// Result возвращает данные, переданные в Writer последним вызовом Process (после всех этапов обработки)
+func (p *MarksProcessor) Result() []models.CordsData {
+	return p.result
+}
+
 // Close закрывает Reader и Writer
 func (p *MarksProcessor) Close() error {
 	var firstErr error
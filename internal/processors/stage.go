@@ -0,0 +1,9 @@
+package processors
+
+import "github.com/rmay1er/jgeo-excel/internal/models"
+
+// Stage этап обработки прочитанных данных перед записью (привязка к зонам, проверки и т.п.)
+type Stage interface {
+	// Apply обрабатывает данные и возвращает результат, который передаётся следующему этапу
+	Apply(data []models.CordsData) ([]models.CordsData, error)
+}
@@ -0,0 +1,125 @@
+package processors
+
+import (
+	"fmt"
+
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/spatial"
+)
+
+// Zone полигон, к которому привязываются точки
+type Zone struct {
+	Name     string
+	ID       string
+	Geometry models.Geometry
+}
+
+// Статусы привязки точки к зоне
+const (
+	ZoneStatusInside  = "в зоне"
+	ZoneStatusOutside = "вне зон"
+	ZoneStatusSkipped = "пропущена"
+)
+
+// ZoneMatch результат привязки одной точки
+type ZoneMatch struct {
+	Data   models.CordsData
+	Zone   *Zone
+	Status string
+}
+
+// ZoneTagger записывает в точки название зоны, в которую они попадают
+type ZoneTagger struct {
+	zones []Zone
+	cfg   config.ZonesConfig
+	// Matches результаты привязки точек последнего вызова Apply
+	Matches []ZoneMatch
+}
+
+// NewZoneTagger создаёт этап привязки точек к зонам
+func NewZoneTagger(zones []Zone, cfg config.ZonesConfig) *ZoneTagger {
+	return &ZoneTagger{zones: zones, cfg: cfg}
+}
+
+// ZonesFromFeatures отбирает полигоны и берёт название зоны из свойства nameFrom
+// (iconCaption - название объекта, id - идентификатор)
+func ZonesFromFeatures(features []models.CordsData, nameFrom string) []Zone {
+	var zones []Zone
+	for _, feature := range features {
+		if !spatial.IsPolygonal(feature.Geometry) {
+			continue
+		}
+
+		var name string
+		switch nameFrom {
+		case config.ZoneNameFromCaption:
+			name = feature.IconCaption
+		case config.ZoneNameFromID:
+			name = feature.ID
+		default:
+			if value, ok := feature.Properties[nameFrom]; ok && value != nil {
+				name = fmt.Sprint(value)
+			}
+		}
+		if name == "" {
+			name = feature.ID
+		}
+		zones = append(zones, Zone{Name: name, ID: feature.ID, Geometry: feature.Geometry})
+	}
+	return zones
+}
+
+// Apply привязывает точки к первой содержащей их зоне. Линии и полигоны не изменяются
+func (t *ZoneTagger) Apply(data []models.CordsData) ([]models.CordsData, error) {
+	t.Matches = nil
+	result := make([]models.CordsData, 0, len(data))
+	inside, outside := 0, 0
+
+	for _, item := range data {
+		point, ok := item.Geometry.(*models.PointGeometry)
+		if !ok || point.IsEmpty() {
+			result = append(result, item)
+			continue
+		}
+
+		zone := t.find(point.Coordinates)
+		if zone != nil {
+			inside++
+			item.SetProperty(t.cfg.Property, zone.Name)
+			if t.cfg.IDProperty != "" && zone.ID != "" {
+				item.SetProperty(t.cfg.IDProperty, zone.ID)
+			}
+			t.Matches = append(t.Matches, ZoneMatch{Data: item, Zone: zone, Status: ZoneStatusInside})
+			result = append(result, item)
+			continue
+		}
+
+		outside++
+		status := ZoneStatusOutside
+		switch t.cfg.Outside {
+		case config.ZonesOutsideSkip:
+			status = ZoneStatusSkipped
+			fmt.Printf("⚠️  Точка '%s' вне всех зон, пропущена\n", item.IconCaption)
+		case config.ZonesOutsideTag:
+			item.SetProperty(t.cfg.Property, t.cfg.OutsideValue)
+		}
+		t.Matches = append(t.Matches, ZoneMatch{Data: item, Status: status})
+		if status != ZoneStatusSkipped {
+			result = append(result, item)
+		}
+	}
+
+	fmt.Printf("🧭 Привязка к зонам (%d): в зонах %d, вне зон %d\n", len(t.zones), inside, outside)
+	return result, nil
+}
+
+// find возвращает первую зону, содержащую точку
+func (t *ZoneTagger) find(pos []float64) *Zone {
+	for i := range t.zones {
+		if spatial.Contains(t.zones[i].Geometry, pos) {
+			return &t.zones[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package readers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// utf8BOM метка порядка байтов, которую Excel и 1С ставят в начало UTF-8 файлов
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// readCSVRows читает текстовую таблицу целиком: определяет кодировку и разделитель
+// и возвращает строки в том же виде, что и строки листа Excel
+func readCSVRows(path string, cfg config.CSVConfig) ([][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть CSV файл: %w", err)
+	}
+
+	text, encoding, err := decodeText(data, cfg.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter := cfg.DelimiterRune()
+	if delimiter == 0 {
+		delimiter = detectDelimiter(text, cfg.QuoteRune())
+	}
+
+	rows, err := parseCSV(text, delimiter, cfg.QuoteRune())
+	if err != nil {
+		return nil, fmt.Errorf("не удалось разобрать CSV файл: %w", err)
+	}
+	fmt.Printf("🔤 CSV: кодировка %s, разделитель %s, строк: %d\n", encoding, delimiterTitle(delimiter), len(rows))
+	return rows, nil
+}
+
+// decodeText переводит содержимое файла в UTF-8 и возвращает название кодировки
+func decodeText(data []byte, encoding string) (string, string, error) {
+	if encoding == config.EncodingAuto {
+		encoding = detectEncoding(data)
+	}
+
+	switch encoding {
+	case config.EncodingUTF8:
+		data = bytes.TrimPrefix(data, utf8BOM)
+		if !utf8.Valid(data) {
+			return "", "", fmt.Errorf("файл не в кодировке UTF-8: укажите excel.csv.encoding (cp1251 или koi8-r)")
+		}
+		return string(data), "UTF-8", nil
+	case config.EncodingCP1251:
+		text, err := charmap.Windows1251.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", "", fmt.Errorf("не удалось перекодировать файл из Windows-1251: %w", err)
+		}
+		return string(text), "Windows-1251", nil
+	case config.EncodingKOI8R:
+		text, err := charmap.KOI8R.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", "", fmt.Errorf("не удалось перекодировать файл из KOI8-R: %w", err)
+		}
+		return string(text), "KOI8-R", nil
+	}
+	return "", "", fmt.Errorf("неизвестная кодировка '%s'", encoding)
+}
+
+// detectEncoding определяет кодировку файла. Всё, что читается как UTF-8, считается UTF-8.
+// Иначе выбирается между Windows-1251 и KOI8-R: в обычном тексте строчных букв больше,
+// чем заглавных, а в Windows-1251 строчные русские буквы занимают байты 0xE0-0xFF,
+// в KOI8-R - 0xC0-0xDF
+func detectEncoding(data []byte) string {
+	if bytes.HasPrefix(data, utf8BOM) || utf8.Valid(data) {
+		return config.EncodingUTF8
+	}
+
+	var upper, lower int
+	for _, b := range data {
+		switch {
+		case b >= 0xE0:
+			lower++
+		case b >= 0xC0:
+			upper++
+		}
+	}
+	if upper > lower {
+		return config.EncodingKOI8R
+	}
+	return config.EncodingCP1251
+}
+
+// detectDelimiter выбирает разделитель, который чаще всего встречается
+// в первой строке вне кавычек: ";", табуляция, "," или "|"
+func detectDelimiter(text string, quote rune) rune {
+	candidates := []rune{';', '\t', ',', '|'}
+	counts := make(map[rune]int)
+	quoted := false
+	for _, r := range text {
+		if quote != 0 && r == quote {
+			quoted = !quoted
+			continue
+		}
+		if quoted {
+			continue
+		}
+		if r == '\n' {
+			break
+		}
+		counts[r]++
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if counts[candidate] > counts[best] {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// parseCSV разбирает текст на строки и поля. Значение в кавычках может содержать
+// разделитель и переводы строк, а кавычка внутри него записывается двумя кавычками.
+// Если quote равен 0, кавычки не обрабатываются
+func parseCSV(text string, delimiter, quote rune) ([][]string, error) {
+	var rows [][]string
+	var row []string
+	var field strings.Builder
+	quoted, line := false, 1
+
+	runes := []rune(strings.ReplaceAll(text, "\r\n", "\n"))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quoted && r == quote:
+			if i+1 < len(runes) && runes[i+1] == quote {
+				field.WriteRune(quote)
+				i++
+				continue
+			}
+			quoted = false
+		case quoted:
+			if r == '\n' {
+				line++
+			}
+			field.WriteRune(r)
+		case quote != 0 && r == quote && strings.TrimSpace(field.String()) == "":
+			field.Reset()
+			quoted = true
+		case r == delimiter:
+			row = append(row, field.String())
+			field.Reset()
+		case r == '\n' || r == '\r':
+			rows = append(rows, append(row, field.String()))
+			row = nil
+			field.Reset()
+			line++
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("строка %d: не закрыта кавычка", line)
+	}
+	if len(row) > 0 || field.Len() > 0 {
+		rows = append(rows, append(row, field.String()))
+	}
+	return rows, nil
+}
+
+// delimiterTitle возвращает разделитель для вывода пользователю
+func delimiterTitle(delimiter rune) string {
+	if delimiter == '\t' {
+		return "табуляция"
+	}
+	return fmt.Sprintf("'%c'", delimiter)
+}
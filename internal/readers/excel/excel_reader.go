@@ -2,36 +2,110 @@ package readers
 
 import (
 	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/crs"
 	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/wkt"
 	"github.com/xuri/excelize/v2"
 )
 
-// ExcelReader читает координаты из Excel файла
+// ExcelReader читает координаты из Excel файла или текстовой таблицы CSV
 type ExcelReader struct {
 	file *excelize.File
+	// csvRows строки текстовой таблицы; для книги Excel - nil, строки читаются из листа file
+	csvRows [][]string
 	// Параметры для чтения
-	sheet    string
-	nameCol  string
-	descCol  string
-	cordsCol string
-	startRow int
+	sheet     string
+	columns   config.ColumnMapping
+	headerRow int
+	startRow  int
+	parser    models.CordsParser
+	// crs система координат таблицы; координаты других систем переводятся в WGS 84
+	crs *crs.CRS
+	// Дополнительные столбцы для свойств объектов
+	properties  []config.PropertyMapping
+	allColumns  bool
+	usesHeaders bool
+	// Настройки цвета маркеров
+	appearance config.AppearanceConfig
+	// Режим сборки линий и полигонов
+	geometry config.GeometryConfig
+	// tableOnly таблица без координат: строки читаются методом ReadTable
+	tableOnly bool
+
+	// Номера колонок (A=1), вычисленные при открытии файла; 0 - колонка не указана
+	nameCol  int
+	descCol  int
+	cordsCol int
+	latCol   int
+	lonCol   int
+	altCol   int
+	wktCol   int
+	idCol    int
+	colorCol int
+	colorBy  int
+	groupCol int
+	seqCol   int
+	ringCol  int
+	// Столбцы оформления: параметр оформления -> номер столбца
+	styleCols map[string]int
+	// Столбцы свойств и заголовки листа (для режима all_columns)
+	propCols []propertyColumn
+	headers  []string
+
+	// rows строки листа, прочитанные последним вызовом Read
+	rows [][]string
+	// rejected строки, не попавшие в результат последнего вызова Read
+	rejected []models.RejectedRow
 }
 
-// NewExcelReader создает новый Excel reader
-func NewExcelReader(path string, sheet, nameCol, descCol, cordsCol string, startRow int) (*ExcelReader, error) {
-	f, err := excelize.OpenFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("не удалось открыть Excel файл: %w", err)
+// propertyColumn столбец, значение которого записывается в свойство mapping.Name
+type propertyColumn struct {
+	mapping config.PropertyMapping
+	idx     int
+}
+
+// NewExcelReader создает новый Excel reader по конфигурации.
+// Настройки внешнего вида (appearance) позволяют брать цвет маркера из столбцов
+func NewExcelReader(cfg config.ExcelConfig, appearance config.AppearanceConfig) (*ExcelReader, error) {
+	system := crs.WGS84CRS
+	if cfg.CRS != "" {
+		var err error
+		if system, err = crs.Parse(cfg.CRS); err != nil {
+			return nil, err
+		}
+	}
+
+	// Прямоугольные координаты записываются в метрах, а не в градусах
+	var parser models.CordsParser = models.DefaultCordsParser{
+		DecimalSeparator: models.DecimalSeparator(cfg.DecimalSeparator),
+		LonLat:           cfg.CoordinateOrder == config.OrderLonLat,
+	}
+	if system.IsProjected() {
+		parser = models.ProjectedCordsParser{
+			DecimalSeparator: models.DecimalSeparator(cfg.DecimalSeparator),
+		}
 	}
 
 	reader := &ExcelReader{
-		file:     f,
-		sheet:    sheet,
-		nameCol:  nameCol,
-		descCol:  descCol,
-		cordsCol: cordsCol,
-		startRow: startRow,
+		sheet:       cfg.Sheet,
+		columns:     cfg.Columns,
+		headerRow:   cfg.HeaderRow,
+		startRow:    cfg.StartRow,
+		parser:      parser,
+		crs:         system,
+		properties:  cfg.Properties,
+		allColumns:  cfg.AllColumns,
+		usesHeaders: cfg.UsesHeaders() || appearance.UsesHeaders(),
+		appearance:  appearance,
+		geometry:    cfg.Geometry,
+	}
+	if err := reader.open(cfg); err != nil {
+		return nil, err
 	}
 
 	// Валидация параметров при создании
@@ -43,98 +117,601 @@ func NewExcelReader(path string, sheet, nameCol, descCol, cordsCol string, start
 	return reader, nil
 }
 
-// validate проверяет корректность параметров
+// NewExcelTableReader создает reader для таблицы без координат (например, показателей по районам).
+// Строки читаются методом ReadTable: ключ строки берётся из столбца идентификатора (excel.columns.id)
+func NewExcelTableReader(cfg config.ExcelConfig) (*ExcelReader, error) {
+	reader := &ExcelReader{
+		sheet:       cfg.Sheet,
+		columns:     cfg.Columns,
+		headerRow:   cfg.HeaderRow,
+		startRow:    cfg.StartRow,
+		properties:  cfg.Properties,
+		allColumns:  cfg.AllColumns,
+		usesHeaders: cfg.UsesHeaders(),
+		tableOnly:   true,
+	}
+	if err := reader.open(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := reader.validate(); err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// open открывает книгу Excel или читает текстовую таблицу, если excel.file - CSV.
+// Для CSV листом считается имя файла, чтобы сообщения об ошибках указывали на него
+func (r *ExcelReader) open(cfg config.ExcelConfig) error {
+	if cfg.IsCSV() {
+		rows, err := readCSVRows(cfg.File, cfg.CSV)
+		if err != nil {
+			return err
+		}
+		r.csvRows, r.sheet = rows, filepath.Base(cfg.File)
+		return nil
+	}
+
+	f, err := excelize.OpenFile(cfg.File)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть Excel файл: %w", err)
+	}
+	r.file = f
+	return nil
+}
+
+// sheetRows возвращает все строки листа или текстовой таблицы. Ячейки читаются с форматом Excel,
+// как их видит пользователь (даты - "15.07.2023"), а столбцы с числами (rawColumns) - без формата:
+// иначе числа теряют точность (3.14159265358979) и получают разделители разрядов ("1 234")
+func (r *ExcelReader) sheetRows() ([][]string, error) {
+	if r.file == nil {
+		return r.csvRows, nil
+	}
+	rows, err := r.file.GetRows(r.sheet)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать строки из листа '%s': %w", r.sheet, err)
+	}
+	columns := r.rawColumns()
+	if len(columns) == 0 {
+		return rows, nil
+	}
+
+	raw, err := r.file.GetRows(r.sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать строки из листа '%s': %w", r.sheet, err)
+	}
+	for i := range rows {
+		if i >= len(raw) {
+			break
+		}
+		for _, idx := range columns {
+			if idx <= len(rows[i]) && idx <= len(raw[i]) {
+				rows[i][idx-1] = raw[i][idx-1]
+			}
+		}
+	}
+	return rows, nil
+}
+
+// rawColumns возвращает номера столбцов (A=1), которые читаются без числового формата:
+// координаты, высота, WKT, порядок точек, оформление и свойства типа number
+func (r *ExcelReader) rawColumns() []int {
+	columns := []int{r.cordsCol, r.latCol, r.lonCol, r.altCol, r.wktCol, r.seqCol}
+	for _, idx := range r.styleCols {
+		columns = append(columns, idx)
+	}
+	for _, prop := range r.propCols {
+		if prop.mapping.Type == config.PropertyNumber {
+			columns = append(columns, prop.idx)
+		}
+	}
+
+	result := columns[:0]
+	for _, idx := range columns {
+		if idx > 0 {
+			result = append(result, idx)
+		}
+	}
+	return result
+}
+
+// validate проверяет корректность параметров и вычисляет номера колонок
 func (r *ExcelReader) validate() error {
-	// Проверяем, существует ли лист
-	sheetIndex, err := r.file.GetSheetIndex(r.sheet)
-	if err != nil || sheetIndex == -1 {
-		return fmt.Errorf("лист '%s' не найден в файле", r.sheet)
+	// Проверяем, существует ли лист (у текстовой таблицы он один)
+	if r.file != nil {
+		sheetIndex, err := r.file.GetSheetIndex(r.sheet)
+		if err != nil || sheetIndex == -1 {
+			return fmt.Errorf("лист '%s' не найден в файле", r.sheet)
+		}
+	}
+
+	if !r.tableOnly {
+		if err := r.columns.ValidateCoordinates(); err != nil {
+			return err
+		}
+	}
+
+	// Заголовки нужны только если хотя бы одна колонка задана по названию
+	if r.usesHeaders {
+		var err error
+		if r.headers, err = r.readHeaders(); err != nil {
+			return err
+		}
 	}
 
 	// Валидируем колонки
-	if r.descCol != "" {
-		if _, err := excelize.ColumnNameToNumber(r.descCol); err != nil {
-			return fmt.Errorf("неверное название колонки для описания: %v", err)
+	for _, col := range []struct {
+		ref   config.ColumnRef
+		idx   *int
+		title string
+	}{
+		{r.columns.Name, &r.nameCol, "названия"},
+		{r.columns.Description, &r.descCol, "описания"},
+		{r.columns.Coordinates, &r.cordsCol, "координат"},
+		{r.columns.Latitude, &r.latCol, "широты"},
+		{r.columns.Longitude, &r.lonCol, "долготы"},
+		{r.columns.Altitude, &r.altCol, "высоты"},
+		{r.columns.WKT, &r.wktCol, "WKT"},
+		{r.columns.ID, &r.idCol, "идентификатора"},
+		{r.appearance.ColorColumn, &r.colorCol, "цвета"},
+		{r.appearance.ColorBy, &r.colorBy, "правил раскраски"},
+		{r.geometry.Group, &r.groupCol, "группы"},
+		{r.geometry.Sequence, &r.seqCol, "порядка точек"},
+		{r.geometry.Ring, &r.ringCol, "контура"},
+	} {
+		idx, err := r.resolveColumn(col.ref)
+		if err != nil {
+			return fmt.Errorf("неверная колонка для %s: %w", col.title, err)
 		}
+		*col.idx = idx
 	}
 
-	if r.cordsCol != "" {
-		if _, err := excelize.ColumnNameToNumber(r.cordsCol); err != nil {
-			return fmt.Errorf("неверное название колонки для координат: %v", err)
+	for key, ref := range r.appearance.StyleColumns {
+		idx, err := r.resolveColumn(ref)
+		if err != nil {
+			return fmt.Errorf("неверная колонка для оформления %s: %w", key, err)
 		}
+		if r.styleCols == nil {
+			r.styleCols = make(map[string]int)
+		}
+		r.styleCols[key] = idx
 	}
 
-	if r.nameCol != "" {
-		if _, err := excelize.ColumnNameToNumber(r.nameCol); err != nil {
-			return fmt.Errorf("неверное название колонки для названия: %v", err)
+	for _, prop := range r.properties {
+		idx, err := r.resolveColumn(prop.Column)
+		if err != nil {
+			return fmt.Errorf("неверная колонка для свойства '%s': %w", prop.Name, err)
 		}
+		r.propCols = append(r.propCols, propertyColumn{mapping: prop, idx: idx})
 	}
 
 	return nil
 }
 
+// readHeaders возвращает значения строки заголовков
+func (r *ExcelReader) readHeaders() ([]string, error) {
+	rows, err := r.sheetRows()
+	if err != nil {
+		return nil, err
+	}
+	if r.headerRow < 1 || r.headerRow > len(rows) {
+		return nil, fmt.Errorf("строка заголовков %d отсутствует на листе '%s'", r.headerRow, r.sheet)
+	}
+	return rows[r.headerRow-1], nil
+}
+
+// resolveColumn переводит ссылку на колонку (букву, номер или заголовок) в её номер (A=1),
+// 0 если колонка не указана
+func (r *ExcelReader) resolveColumn(ref config.ColumnRef) (int, error) {
+	if ref.Column != "" {
+		if idx, err := strconv.Atoi(ref.Column); err == nil {
+			if idx < 1 {
+				return 0, fmt.Errorf("номер столбца должен начинаться с 1, получено %d", idx)
+			}
+			return idx, nil
+		}
+		return excelize.ColumnNameToNumber(ref.Column)
+	}
+	if ref.Header == "" {
+		return 0, nil
+	}
+
+	title := strings.TrimSpace(ref.Header)
+	for i, header := range r.headers {
+		if strings.TrimSpace(header) == title {
+			return i + 1, nil
+		}
+	}
+	for i, header := range r.headers {
+		if strings.EqualFold(strings.TrimSpace(header), title) {
+			return i + 1, nil
+		}
+	}
+
+	var available []string
+	for i, header := range r.headers {
+		if header = strings.TrimSpace(header); header != "" {
+			col, _ := excelize.ColumnNumberToName(i + 1)
+			available = append(available, fmt.Sprintf("%s «%s»", col, header))
+		}
+	}
+	return 0, fmt.Errorf("заголовок «%s» не найден в строке %d. Доступные заголовки: %s",
+		ref.Header, r.headerRow, strings.Join(available, ", "))
+}
+
 // Read читает координаты из Excel файла
 func (r *ExcelReader) Read() (*[]models.CordsData, error) {
 	// Получаем все строки из листа
-	rows, err := r.file.GetRows(r.sheet)
+	rows, err := r.sheetRows()
 	if err != nil {
-		return nil, fmt.Errorf("не удалось прочитать строки из листа '%s': %w", r.sheet, err)
+		return nil, err
 	}
 
 	if len(rows) == 0 {
 		return nil, fmt.Errorf("лист '%s' пуст", r.sheet)
 	}
-
-	// Конвертируем буквы колонок в индексы (A=1, B=2, C=3, ...)
-	var nameColIdx int
-	if r.nameCol != "" {
-		nameColIdx, _ = excelize.ColumnNameToNumber(r.nameCol)
-	}
-
-	descColIdx, _ := excelize.ColumnNameToNumber(r.descCol)
-	cordsColIdx, _ := excelize.ColumnNameToNumber(r.cordsCol)
+	r.rows, r.rejected = rows, nil
 
 	var result []models.CordsData
+	var vertices []vertex
+	notations := make(map[models.CordsNotation]int)
 
 	// Начинаем с указанной строки (startRow обычно 2, т.к. 1я - заголовки)
 	// startRow идет с 1, а индекс массива rows начинается с 0
 	for i := r.startRow - 1; i < len(rows); i++ {
 		row := rows[i]
+		var cordsData models.CordsData
+
+		switch {
+		case r.wktCol > 0:
+			text := cellValue(row, r.wktCol)
+			if text == "" {
+				// Пустая ячейка WKT у заполненной строки - объект без геометрии (geometry: null),
+				// так to-excel записывает такие объекты
+				if !rowHasData(row) {
+					continue
+				}
+				break
+			}
 
-		// Проверяем, что строка содержит координаты (это обязательное поле)
-		if len(row) >= cordsColIdx && row[cordsColIdx-1] != "" {
-			var cordsData models.CordsData
+			geometry, err := wkt.Parse(text)
+			if err == nil {
+				err = geometry.Validate()
+			}
+			if err != nil {
+				r.reject(i+1, r.wktCol, models.RejectWKT, fmt.Sprintf("ошибка при разборе WKT: %v", err))
+				continue
+			}
+			cordsData.Geometry = geometry
+			cordsData.Notation = models.NotationDecimal
+		case r.cordsCol > 0:
+			// Проверяем, что строка содержит координаты (это обязательное поле)
+			cords := cellValue(row, r.cordsCol)
+			if cords == "" {
+				continue
+			}
 
 			// Добавляем координаты
-			if err := cordsData.SetCords(row[cordsColIdx-1]); err != nil {
+			if err := cordsData.SetCords(cords, r.parser); err != nil {
 				// Пропускаем строку с ошибкой парсинга
-				fmt.Printf("⚠️  Пропущена строка %d: ошибка при парсинге координат '%s'\n", i+1, row[cordsColIdx-1])
+				r.reject(i+1, r.cordsCol, models.RejectCoordinates, fmt.Sprintf("ошибка при парсинге координат '%s': %v", cords, err))
+				continue
+			}
+		default:
+			lat, lon := cellValue(row, r.latCol), cellValue(row, r.lonCol)
+			if lat == "" && lon == "" {
+				continue
+			}
+			if lat == "" || lon == "" {
+				col := r.latCol
+				if lat != "" {
+					col = r.lonCol
+				}
+				r.reject(i+1, col, models.RejectIncomplete, "не заполнена широта или долгота")
 				continue
 			}
 
-			// Берем имя из соответствующей колонки (опционально, если колонка указана)
-			if nameColIdx > 0 && len(row) >= nameColIdx && row[nameColIdx-1] != "" {
-				cordsData.IconCaption = row[nameColIdx-1]
+			alt := cellValue(row, r.altCol)
+			if err := cordsData.SetLatLon(lat, lon, alt, r.parser); err != nil {
+				r.reject(i+1, r.invalidAxisColumn(lat, lon), models.RejectCoordinates, fmt.Sprintf("ошибка при парсинге координат: %v", err))
+				continue
 			}
+		}
+
+		if !r.crs.IsWGS84() && cordsData.Geometry != nil {
+			geometry, err := r.crs.GeometryToWGS84(cordsData.Geometry)
+			if err != nil {
+				r.reject(i+1, r.geometryColumn(), models.RejectCRS, err.Error())
+				continue
+			}
+			cordsData.Geometry = geometry
+		}
+		cordsData.Row = i + 1
 
-			// Берем описание из соответствующей колонки
-			if len(row) >= descColIdx && row[descColIdx-1] != "" {
-				cordsData.Description = row[descColIdx-1]
+		if cordsData.Geometry != nil {
+			if cordsData.Notation != models.NotationDecimal {
+				fmt.Printf("📐 Строка %d: координаты в формате %s\n", i+1, cordsData.Notation)
 			}
+			notations[cordsData.Notation]++
+		}
+
+		// Берем имя из соответствующей колонки (опционально, если колонка указана)
+		cordsData.IconCaption = cellValue(row, r.nameCol)
+
+		// Берем описание из соответствующей колонки
+		cordsData.Description = cellValue(row, r.descCol)
+
+		// Идентификатор объекта (опционально)
+		cordsData.ID = cellValue(row, r.idCol)
+
+		// Дополнительные свойства
+		r.readProperties(row, i+1, &cordsData)
+
+		// Оформление: цвет маркера из столбца или по правилам и остальные параметры из столбцов
+		r.readStyle(row, i+1, &cordsData)
 
-			// Добавляем объект в результат
+		// В режиме точек каждая строка - отдельный объект,
+		// иначе строка - вершина линии или полигона своей группы
+		if r.geometry.Type == "" || r.geometry.Type == config.GeometryPoint {
 			result = append(result, cordsData)
+			continue
 		}
+
+		group := cellValue(row, r.groupCol)
+		if group == "" {
+			r.reject(i+1, r.groupCol, models.RejectGroup, "не заполнен ключ группы")
+			continue
+		}
+		vertices = append(vertices, vertex{
+			data:     cordsData,
+			row:      i + 1,
+			group:    group,
+			sequence: cellValue(row, r.seqCol),
+			ring:     cellValue(row, r.ringCol),
+		})
+	}
+
+	if len(vertices) > 0 {
+		result = buildGeometries(vertices, r.geometry.Type, func(v vertex, message string) {
+			r.Reject(models.RejectedRow{Row: v.row, Reason: models.RejectGeometry, Message: message})
+		})
+		fmt.Printf("🔷 Собрано объектов типа %s: %d из %d строк\n", r.geometry.Type, len(result), len(vertices))
 	}
 
 	if len(result) == 0 {
 		return nil, fmt.Errorf("не найдено координат в указанных колонках на листе '%s'", r.sheet)
 	}
 
+	if !r.crs.IsWGS84() {
+		fmt.Printf("🌐 Координаты переведены из %s в WGS 84\n", r.crs)
+	}
+
+	fmt.Printf("📐 Форматы координат: %s=%d, %s=%d, %s=%d\n",
+		models.NotationDecimal, notations[models.NotationDecimal],
+		models.NotationDDM, notations[models.NotationDDM],
+		models.NotationDMS, notations[models.NotationDMS])
+
 	return &result, nil
 }
 
+// rowHasData сообщает, что в строке заполнена хотя бы одна ячейка
+func rowHasData(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// reject запоминает строку, не попавшую в результат, и выводит предупреждение
+func (r *ExcelReader) reject(rowNum, col int, reason models.RejectReason, message string) {
+	fmt.Printf("⚠️  Пропущена строка %d: %s\n", rowNum, message)
+	r.Reject(models.RejectedRow{Row: rowNum, Column: col, Reason: reason, Message: message})
+}
+
+// Reject добавляет строку, отклонённую после чтения (например, этапом проверки координат).
+// Если значения ячеек не заданы, они берутся из прочитанного листа,
+// а если не задан столбец, ошибка относится к столбцу геометрии
+func (r *ExcelReader) Reject(row models.RejectedRow) {
+	if row.Column == 0 {
+		row.Column = r.geometryColumn()
+	}
+	if row.Values == nil && row.Row >= 1 && row.Row <= len(r.rows) {
+		row.Values = append([]string(nil), r.rows[row.Row-1]...)
+	}
+	r.rejected = append(r.rejected, row)
+}
+
+// Rejected возвращает строки, не попавшие в результат последнего вызова Read, в порядке обнаружения
+func (r *ExcelReader) Rejected() []models.RejectedRow {
+	return r.rejected
+}
+
+// Headers возвращает заголовки столбцов листа; для столбцов без заголовка - букву столбца
+func (r *ExcelReader) Headers(count int) []string {
+	headers := make([]string, count)
+	for i := range headers {
+		headers[i] = r.columnTitle(i + 1)
+	}
+	return headers
+}
+
+// DataColumns возвращает номера столбцов (A=1), указанных в конфигурации для чтения данных
+func (r *ExcelReader) DataColumns() []int {
+	columns := []int{
+		r.nameCol, r.descCol, r.cordsCol, r.latCol, r.lonCol, r.altCol, r.wktCol, r.idCol,
+		r.colorCol, r.colorBy, r.groupCol, r.seqCol, r.ringCol,
+	}
+	for _, idx := range r.styleCols {
+		columns = append(columns, idx)
+	}
+	for _, prop := range r.propCols {
+		columns = append(columns, prop.idx)
+	}
+
+	result := columns[:0]
+	for _, idx := range columns {
+		if idx > 0 {
+			result = append(result, idx)
+		}
+	}
+	return result
+}
+
+// geometryColumn возвращает столбец, из которого читается геометрия
+func (r *ExcelReader) geometryColumn() int {
+	switch {
+	case r.wktCol > 0:
+		return r.wktCol
+	case r.cordsCol > 0:
+		return r.cordsCol
+	}
+	return r.latCol
+}
+
+// invalidAxisColumn возвращает первый из столбцов широты, долготы и высоты, значение которого не разбирается
+func (r *ExcelReader) invalidAxisColumn(lat, lon string) int {
+	if _, _, err := r.parser.ParseAxis(lat, models.AxisLatitude); err != nil {
+		return r.latCol
+	}
+	if _, _, err := r.parser.ParseAxis(lon, models.AxisLongitude); err != nil {
+		return r.lonCol
+	}
+	return r.altCol
+}
+
+// ReadTable читает строки таблицы без координат: ключ (ID), название, описание и свойства.
+// Строки без ключа пропускаются
+func (r *ExcelReader) ReadTable() (*[]models.CordsData, error) {
+	rows, err := r.sheetRows()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.CordsData
+	for i := r.startRow - 1; i < len(rows); i++ {
+		row := rows[i]
+		key := cellValue(row, r.idCol)
+		if key == "" {
+			continue
+		}
+
+		data := models.CordsData{
+			ID:          key,
+			IconCaption: cellValue(row, r.nameCol),
+			Description: cellValue(row, r.descCol),
+		}
+		r.readProperties(row, i+1, &data)
+		result = append(result, data)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("не найдено строк с ключом на листе '%s'", r.sheet)
+	}
+	return &result, nil
+}
+
+// readProperties переносит дополнительные столбцы строки в свойства объекта
+func (r *ExcelReader) readProperties(row []string, rowNum int, cordsData *models.CordsData) {
+	if r.allColumns {
+		// Столбцы, из которых уже читаются данные (в том числе группа, порядок точек, контур,
+		// раскраска и явно заданные свойства), повторно не добавляются
+		used := make(map[int]bool)
+		for _, idx := range r.DataColumns() {
+			used[idx] = true
+		}
+		for i := range row {
+			idx := i + 1
+			value := cellValue(row, idx)
+			if used[idx] || value == "" {
+				continue
+			}
+			cordsData.SetProperty(r.columnTitle(idx), value)
+		}
+	}
+
+	for _, prop := range r.propCols {
+		raw := cellValue(row, prop.idx)
+		if raw == "" {
+			continue
+		}
+		value, err := prop.mapping.ConvertValue(raw)
+		if err != nil {
+			fmt.Printf("⚠️  Строка %d: свойство '%s': %v, записано как текст\n", rowNum, prop.mapping.Name, err)
+			value = raw
+		}
+		cordsData.SetProperty(prop.mapping.Name, value)
+	}
+}
+
+// readStyle заполняет оформление объекта из столбцов строки
+func (r *ExcelReader) readStyle(row []string, rowNum int, cordsData *models.CordsData) {
+	if color := r.resolveColor(row, rowNum); color != "" {
+		cordsData.Style.Set(models.StyleMarkerColor, color)
+	}
+
+	for key, idx := range r.styleCols {
+		raw := cellValue(row, idx)
+		if raw == "" {
+			continue
+		}
+		value, err := config.NormalizeStyleValue(key, raw)
+		if err != nil {
+			fmt.Printf("⚠️  Строка %d: %v, используется оформление по умолчанию\n", rowNum, err)
+			continue
+		}
+		cordsData.Style.Set(key, value)
+	}
+}
+
+// resolveColor определяет цвет маркера строки: сначала из столбца цвета,
+// затем по правилам раскраски. Пустая строка означает цвет по умолчанию
+func (r *ExcelReader) resolveColor(row []string, rowNum int) string {
+	if value := cellValue(row, r.colorCol); value != "" {
+		color, err := config.NormalizeColor(value)
+		if err == nil {
+			return color
+		}
+		fmt.Printf("⚠️  Строка %d: %v, используется цвет по умолчанию\n", rowNum, err)
+	}
+
+	if r.colorBy > 0 {
+		value := cellValue(row, r.colorBy)
+		for i := range r.appearance.ColorRules {
+			if r.appearance.ColorRules[i].Matches(value) {
+				return r.appearance.ColorRules[i].Color
+			}
+		}
+	}
+
+	return ""
+}
+
+// columnTitle возвращает заголовок столбца, а если его нет - букву столбца
+func (r *ExcelReader) columnTitle(idx int) string {
+	if title := cellValue(r.headers, idx); title != "" {
+		return title
+	}
+	col, _ := excelize.ColumnNumberToName(idx)
+	return col
+}
+
+// cellValue возвращает значение ячейки строки по номеру колонки (с 1)
+func cellValue(row []string, idx int) string {
+	if idx <= 0 || len(row) < idx {
+		return ""
+	}
+	return strings.TrimSpace(row[idx-1])
+}
+
 // Close закрывает Excel файл
 func (r *ExcelReader) Close() error {
+	if r.file == nil {
+		r.csvRows = nil
+		return nil
+	}
 	return r.file.Close()
 }
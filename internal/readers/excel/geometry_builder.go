@@ -0,0 +1,180 @@
+package readers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// vertex вершина линии или полигона, прочитанная из одной строки
+type vertex struct {
+	data     models.CordsData
+	row      int
+	group    string
+	sequence string
+	ring     string
+}
+
+// buildGeometries собирает вершины с одинаковым ключом группы в линии или полигоны.
+// Группы выводятся в порядке первого появления, вершины упорядочиваются по sequence,
+// а при его отсутствии - по порядку строк. Свойства объекта берутся из первой строки группы.
+// Для каждой строки группы, из которой не удалось собрать геометрию, вызывается reject
+func buildGeometries(vertices []vertex, geometryType string, reject func(v vertex, message string)) []models.CordsData {
+	var order []string
+	groups := make(map[string][]vertex)
+	for _, v := range vertices {
+		if _, ok := groups[v.group]; !ok {
+			order = append(order, v.group)
+		}
+		groups[v.group] = append(groups[v.group], v)
+	}
+
+	var result []models.CordsData
+	for _, key := range order {
+		group := groups[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			return lessSequence(group[i].sequence, group[j].sequence)
+		})
+
+		feature := mergeGroupAttributes(group)
+		var err error
+		switch geometryType {
+		case config.GeometryLineString:
+			feature.Geometry, err = buildLineString(group)
+		case config.GeometryPolygon:
+			feature.Geometry, err = buildPolygon(group)
+		}
+		if err != nil {
+			fmt.Printf("⚠️  Пропущена группа '%s' (строки %s): %v\n", key, groupRows(group), err)
+			for _, v := range group {
+				reject(v, fmt.Sprintf("группа '%s': %v", key, err))
+			}
+			continue
+		}
+
+		result = append(result, feature)
+	}
+
+	return result
+}
+
+// mergeGroupAttributes берёт идентификатор, название, описание, свойства и оформление
+// из первых заполненных строк группы
+func mergeGroupAttributes(group []vertex) models.CordsData {
+	var feature models.CordsData
+	for _, v := range group {
+		if feature.ID == "" {
+			feature.ID = v.data.ID
+		}
+		if feature.IconCaption == "" {
+			feature.IconCaption = v.data.IconCaption
+		}
+		if feature.Description == "" {
+			feature.Description = v.data.Description
+		}
+		for key, value := range v.data.Properties {
+			if _, ok := feature.Properties[key]; !ok {
+				feature.SetProperty(key, value)
+			}
+		}
+		for key, value := range v.data.Style {
+			if _, ok := feature.Style[key]; !ok {
+				feature.Style.Set(key, value)
+			}
+		}
+		feature.Rows = append(feature.Rows, v.row)
+	}
+	feature.Notation = group[0].data.Notation
+	feature.Row = group[0].row
+	return feature
+}
+
+// buildLineString собирает линию из упорядоченных вершин
+func buildLineString(group []vertex) (models.Geometry, error) {
+	line := positions(group)
+	if len(line) < 2 {
+		return nil, fmt.Errorf("для линии нужно минимум 2 точки, найдено %d", len(line))
+	}
+	return models.NewLineStringGeometry(line...), nil
+}
+
+// buildPolygon собирает полигон: контуры группируются по номеру ring,
+// внешний контур - с наименьшим номером, остальные - дырки. Незамкнутые контуры замыкаются
+func buildPolygon(group []vertex) (models.Geometry, error) {
+	var ringOrder []string
+	rings := make(map[string][]vertex)
+	for _, v := range group {
+		if _, ok := rings[v.ring]; !ok {
+			ringOrder = append(ringOrder, v.ring)
+		}
+		rings[v.ring] = append(rings[v.ring], v)
+	}
+	sort.SliceStable(ringOrder, func(i, j int) bool {
+		return lessSequence(ringOrder[i], ringOrder[j])
+	})
+
+	polygon := make([][][]float64, 0, len(ringOrder))
+	for _, key := range ringOrder {
+		ring := positions(rings[key])
+		if len(ring) > 0 && !samePosition(ring[0], ring[len(ring)-1]) {
+			ring = append(ring, ring[0])
+		}
+		if len(ring) < 4 {
+			return nil, fmt.Errorf("контур '%s' содержит меньше 3 различных точек", key)
+		}
+		polygon = append(polygon, ring)
+	}
+	return models.NewPolygonGeometry(polygon...), nil
+}
+
+// positions извлекает координаты вершин
+func positions(group []vertex) [][]float64 {
+	result := make([][]float64, 0, len(group))
+	for _, v := range group {
+		if point, ok := v.data.Geometry.(*models.PointGeometry); ok && !point.IsEmpty() {
+			result = append(result, point.Coordinates)
+		}
+	}
+	return result
+}
+
+// samePosition сравнивает две точки
+func samePosition(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lessSequence сравнивает значения порядкового столбца: числа - как числа, остальное - как строки
+func lessSequence(a, b string) bool {
+	na, errA := strconv.ParseFloat(strings.ReplaceAll(a, ",", "."), 64)
+	nb, errB := strconv.ParseFloat(strings.ReplaceAll(b, ",", "."), 64)
+	switch {
+	case errA == nil && errB == nil:
+		return na < nb
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	}
+	return a < b
+}
+
+// groupRows возвращает номера строк группы для сообщений об ошибках
+func groupRows(group []vertex) string {
+	rows := make([]string, 0, len(group))
+	for _, v := range group {
+		rows = append(rows, strconv.Itoa(v.row))
+	}
+	return strings.Join(rows, ", ")
+}
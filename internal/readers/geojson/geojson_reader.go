@@ -1,7 +1,9 @@
 package geojson
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 
 	geojson "github.com/paulmach/go.geojson"
 	"github.com/rmay1er/jgeo-excel/internal/models"
@@ -30,38 +32,76 @@ func (r *GeoJSONReader) Read() (*[]models.CordsData, error) {
 		return nil, err
 	}
 
-	for _, feture := range geoCollection.Features {
-		name, ok := feture.Properties["iconCaption"].(string)
-		if !ok {
-			name = ""
-		}
-		desc, ok := feture.Properties["description"].(string)
-		if !ok {
-			desc = ""
+	for i, feture := range geoCollection.Features {
+		newFeture, err := FeatureToCordsData(feture)
+		if err != nil {
+			return nil, fmt.Errorf("объект %d: %w", i+1, err)
 		}
+		parsed = append(parsed, newFeture)
+	}
+
+	return &parsed, nil
+}
+
+// FeatureToCordsData преобразует объект GeoJSON: iconCaption и description - в название и описание,
+// параметры simplestyle - в оформление, остальные свойства переносятся без изменений
+func FeatureToCordsData(feture *geojson.Feature) (models.CordsData, error) {
+	name, ok := feture.Properties["iconCaption"].(string)
+	if !ok {
+		name = ""
+	}
+	desc, ok := feture.Properties["description"].(string)
+	if !ok {
+		desc = ""
+	}
+
+	// Объект без геометрии (geometry: null) сохраняется с Geometry == nil
+	geometry, err := models.GeometryFromGeoJSON(feture.Geometry)
+	if err != nil {
+		return models.CordsData{}, err
+	}
+
+	newFeture := models.CordsData{
+		ID:          featureID(feture.ID),
+		IconCaption: name,
+		Description: desc,
+		Geometry:    geometry,
+	}
 
-		var cords any
-		switch feture.Geometry.Type {
-		case geojson.GeometryPoint:
-			cords = feture.Geometry.Point
-		case geojson.GeometryLineString:
-			cords = feture.Geometry.LineString
-		case geojson.GeometryPolygon:
-			cords = feture.Geometry.Polygon
+	// Оформление и остальные свойства переносим без изменений
+	for key, value := range feture.Properties {
+		switch {
+		case key == "iconCaption" || key == "description":
+		case isStyleKey(key):
+			newFeture.Style.Set(key, value)
 		default:
-			cords = nil
+			newFeture.SetProperty(key, value)
 		}
+	}
+	return newFeture, nil
+}
 
-		newFeture := models.CordsData{
-			Type:        string(feture.Geometry.Type),
-			IconCaption: name,
-			Description: desc,
-			Cords:       cords,
-		}
-		parsed = append(parsed, newFeture)
+// featureID приводит id объекта GeoJSON (строку или число) к строке
+func featureID(id any) string {
+	switch v := id.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
 	}
+	return fmt.Sprint(id)
+}
 
-	return &parsed, nil
+// isStyleKey сообщает, является ли свойство параметром оформления
+func isStyleKey(key string) bool {
+	for _, styleKey := range models.StyleKeys {
+		if key == styleKey {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *GeoJSONReader) Close() error {
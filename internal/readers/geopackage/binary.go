@@ -0,0 +1,49 @@
+package geopackage
+
+import (
+	"fmt"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/wkb"
+)
+
+// Флаги заголовка GeoPackageBinary
+const (
+	flagEmpty    = 1 << 4
+	flagExtended = 1 << 5
+)
+
+// envelopeSizes размер охвата в байтах по его виду (биты 1-3 флагов): нет, XY, XYZ, XYM, XYZM
+var envelopeSizes = []int{0, 32, 48, 48, 64}
+
+// decodeGeometry разбирает геометрию GeoPackageBinary: заголовок GP и WKB.
+// Пустая геометрия возвращается как nil
+func decodeGeometry(blob []byte) (models.Geometry, error) {
+	if len(blob) < 8 || blob[0] != 'G' || blob[1] != 'P' {
+		return nil, fmt.Errorf("геометрия не в формате GeoPackageBinary")
+	}
+	flags := blob[3]
+	if flags&flagExtended != 0 {
+		return nil, fmt.Errorf("расширенный формат геометрии GeoPackage не поддерживается")
+	}
+	envelope := int(flags>>1) & 0x07
+	if envelope >= len(envelopeSizes) {
+		return nil, fmt.Errorf("неверный вид охвата геометрии: %d", envelope)
+	}
+	start := 8 + envelopeSizes[envelope]
+	if len(blob) < start {
+		return nil, fmt.Errorf("геометрия обрезана")
+	}
+	if flags&flagEmpty != 0 && len(blob) == start {
+		return nil, nil
+	}
+
+	geometry, err := wkb.Parse(blob[start:])
+	if err != nil {
+		return nil, err
+	}
+	if geometry.IsEmpty() {
+		return nil, nil
+	}
+	return geometry, nil
+}
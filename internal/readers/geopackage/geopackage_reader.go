@@ -0,0 +1,313 @@
+// Package geopackage читает объекты из файла GeoPackage (.gpkg) - базы SQLite по стандарту OGC.
+//
+// Читаются все слои объектов (features) в порядке их регистрации в gpkg_contents. Столбцы name,
+// description (descr) и id становятся названием, описанием и идентификатором, столбцы оформления
+// (marker-color, stroke и т.д.) - оформлением, остальные - свойствами. Координаты системы слоя
+// из gpkg_spatial_ref_sys переводятся в WGS 84. База открывается только для чтения драйвером
+// SQLite на чистом Go, без cgo
+package geopackage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rmay1er/jgeo-excel/internal/crs"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	_ "modernc.org/sqlite"
+)
+
+// GeoPackageReader читает объекты из .gpkg
+type GeoPackageReader struct {
+	path string
+}
+
+// NewGeoPackageReader создаёт reader для файла GeoPackage
+func NewGeoPackageReader(path string) (*GeoPackageReader, error) {
+	return &GeoPackageReader{path: path}, nil
+}
+
+// layer слой объектов: таблица, столбец геометрии и код системы координат
+type layer struct {
+	table  string
+	column string
+	srsID  int
+}
+
+// Read читает объекты всех слоёв GeoPackage
+func (r *GeoPackageReader) Read() (*[]models.CordsData, error) {
+	// Драйвер SQLite создаёт отсутствующий файл, поэтому его наличие проверяется заранее
+	if _, err := os.Stat(r.path); err != nil {
+		return nil, fmt.Errorf("не удалось открыть GeoPackage: %w", err)
+	}
+	uri, err := readOnlyURI(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть GeoPackage: %w", err)
+	}
+	db, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть GeoPackage: %w", err)
+	}
+	defer db.Close()
+
+	layers, err := readLayers(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("в GeoPackage %s нет слоёв объектов", r.path)
+	}
+
+	result := []models.CordsData{}
+	for _, l := range layers {
+		items, err := l.read(db)
+		if err != nil {
+			return nil, fmt.Errorf("слой %s: %w", l.table, err)
+		}
+		if len(layers) > 1 {
+			fmt.Printf("🗂️  Слой %s: объектов - %d\n", l.table, len(items))
+		}
+		result = append(result, items...)
+	}
+	return &result, nil
+}
+
+// Close ничего не делает: база открывается и закрывается в Read
+func (r *GeoPackageReader) Close() error {
+	return nil
+}
+
+// readOnlyURI возвращает URI SQLite для открытия файла только для чтения. Путь экранируется,
+// чтобы символы ?, # и % в имени файла не считались частью URI
+func readOnlyURI(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	abs = filepath.ToSlash(abs)
+	if !strings.HasPrefix(abs, "/") {
+		// Путь Windows с буквой диска: file:///C:/...
+		abs = "/" + abs
+	}
+	uri := url.URL{Scheme: "file", Path: abs, RawQuery: "mode=ro"}
+	return uri.String(), nil
+}
+
+// readLayers возвращает слои объектов в порядке их регистрации
+func readLayers(db *sql.DB) ([]layer, error) {
+	rows, err := db.Query(`SELECT c.table_name, g.column_name, g.srs_id
+		FROM gpkg_contents c JOIN gpkg_geometry_columns g ON g.table_name = c.table_name
+		WHERE c.data_type = 'features'
+		ORDER BY c.rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("файл не является GeoPackage: %w", err)
+	}
+	defer rows.Close()
+
+	var layers []layer
+	for rows.Next() {
+		var l layer
+		if err := rows.Scan(&l.table, &l.column, &l.srsID); err != nil {
+			return nil, fmt.Errorf("не удалось прочитать список слоёв: %w", err)
+		}
+		layers = append(layers, l)
+	}
+	return layers, rows.Err()
+}
+
+// tableColumn столбец таблицы слоя и его объявленный тип
+type tableColumn struct {
+	name string
+	kind string
+	// json столбец описан в gpkg_data_columns как application/json
+	json bool
+}
+
+// read читает объекты слоя и переводит координаты в WGS 84
+func (l layer) read(db *sql.DB) ([]models.CordsData, error) {
+	system, err := l.system(db)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := l.columns(db)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{quote(l.column)}
+	for _, col := range columns {
+		names = append(names, quote(col.name))
+	}
+	rows, err := db.Query(fmt.Sprintf("SELECT %s FROM %s", strings.Join(names, ", "), quote(l.table)))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать объекты: %w", err)
+	}
+	defer rows.Close()
+
+	result := []models.CordsData{}
+	for i := 1; rows.Next(); i++ {
+		var blob []byte
+		values := make([]any, len(columns))
+		targets := []any{&blob}
+		for j := range values {
+			targets = append(targets, &values[j])
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("объект %d: %w", i, err)
+		}
+
+		item := models.CordsData{Notation: models.NotationDecimal}
+		if blob != nil {
+			geometry, err := decodeGeometry(blob)
+			if err != nil {
+				return nil, fmt.Errorf("объект %d: %w", i, err)
+			}
+			if geometry != nil && !system.IsWGS84() {
+				if geometry, err = system.GeometryToWGS84(geometry); err != nil {
+					return nil, fmt.Errorf("объект %d: %w", i, err)
+				}
+			}
+			item.Geometry = geometry
+		}
+		for j, col := range columns {
+			setAttribute(&item, col, values[j])
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+// system находит систему координат слоя: по коду EPSG или по описанию WKT из gpkg_spatial_ref_sys
+func (l layer) system(db *sql.DB) (*crs.CRS, error) {
+	if l.srsID == 0 || l.srsID == -1 {
+		fmt.Printf("⚠️  %s: система координат не задана, координаты считаются градусами WGS 84\n", l.table)
+		return crs.WGS84CRS, nil
+	}
+
+	var organization, definition string
+	var code int
+	err := db.QueryRow(`SELECT organization, organization_coordsys_id, definition
+		FROM gpkg_spatial_ref_sys WHERE srs_id = ?`, l.srsID).Scan(&organization, &code, &definition)
+	if err != nil {
+		return nil, fmt.Errorf("система координат %d не найдена в gpkg_spatial_ref_sys: %w", l.srsID, err)
+	}
+
+	system, err := crs.ParsePRJ(definition)
+	if strings.EqualFold(organization, "EPSG") {
+		if byCode, codeErr := crs.Parse(fmt.Sprintf("EPSG:%d", code)); codeErr == nil {
+			system, err = byCode, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("система координат %s:%d: %w", organization, code, err)
+	}
+	if !system.IsWGS84() {
+		fmt.Printf("🌐 %s: координаты переводятся из %s в WGS 84\n", l.table, system)
+	}
+	return system, nil
+}
+
+// columns возвращает столбцы атрибутов слоя: все, кроме первичного ключа и геометрии
+func (l layer) columns(db *sql.DB) ([]tableColumn, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", quote(l.table)))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать столбцы: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []tableColumn
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, kind string
+		var defaultValue any
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &defaultValue, &pk); err != nil {
+			return nil, fmt.Errorf("не удалось прочитать столбцы: %w", err)
+		}
+		if pk > 0 || strings.EqualFold(name, l.column) {
+			continue
+		}
+		columns = append(columns, tableColumn{name: name, kind: strings.ToUpper(kind)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Описание столбцов есть только в базах с расширением gpkg_schema
+	jsonColumns := make(map[string]bool)
+	if described, err := db.Query(`SELECT column_name FROM gpkg_data_columns
+		WHERE table_name = ? AND mime_type = 'application/json'`, l.table); err == nil {
+		for described.Next() {
+			var name string
+			if described.Scan(&name) == nil {
+				jsonColumns[strings.ToLower(name)] = true
+			}
+		}
+		described.Close()
+	}
+	for i := range columns {
+		columns[i].json = jsonColumns[strings.ToLower(columns[i].name)]
+	}
+	return columns, nil
+}
+
+// setAttribute переносит значение столбца: name, description (descr) и id - в поля объекта,
+// оформление - в Style, остальные - в свойства. Целые числа становятся float64, как в GeoJSON,
+// BOOLEAN - логическими значениями, столбцы application/json - объектами и массивами;
+// двоичные данные пропускаются
+func setAttribute(item *models.CordsData, col tableColumn, value any) {
+	switch v := value.(type) {
+	case nil, []byte:
+		return
+	case int64:
+		if col.kind == "BOOLEAN" {
+			value = v != 0
+		} else {
+			value = float64(v)
+		}
+	case string:
+		var decoded any
+		if col.json && json.Unmarshal([]byte(v), &decoded) == nil {
+			value = decoded
+		}
+	case time.Time:
+		if col.kind == "DATE" {
+			value = v.Format(time.DateOnly)
+		} else {
+			value = v.Format(time.RFC3339)
+		}
+	}
+
+	text, isText := value.(string)
+	switch strings.ToLower(col.name) {
+	case "name":
+		if isText {
+			item.IconCaption = text
+			return
+		}
+	case "description", "descr":
+		if isText {
+			item.Description = text
+			return
+		}
+	case "id":
+		item.ID = fmt.Sprint(value)
+		return
+	}
+	if slices.Contains(models.StyleKeys, col.name) {
+		item.Style.Set(col.name, value)
+		return
+	}
+	item.SetProperty(col.name, value)
+}
+
+// quote заключает имя таблицы или столбца SQLite в двойные кавычки
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
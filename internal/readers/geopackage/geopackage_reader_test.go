@@ -0,0 +1,104 @@
+package geopackage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	writer "github.com/rmay1er/jgeo-excel/internal/writers/geopackage"
+)
+
+// TestWriteRead записывает объекты GeoPackageWriter в один файл и читает их обратно
+func TestWriteRead(t *testing.T) {
+	items := []models.CordsData{
+		{
+			ID:          "7",
+			IconCaption: "Колодец",
+			Description: "у дороги",
+			Geometry:    models.NewPointGeometry(37.6173, 55.7558),
+			Properties:  map[string]any{"depth": 12.5, "kind": "бетон"},
+			Style:       models.Style{models.StyleMarkerColor: "#ff0000"},
+		},
+		{
+			IconCaption: "Опора",
+			Geometry:    models.NewPointGeometry(37.6173, 55.7558, 150),
+		},
+		{
+			IconCaption: "Трасса",
+			Geometry:    models.NewLineStringGeometry([]float64{37.61, 55.75}, []float64{37.62, 55.76}, []float64{37.63, 55.75}),
+		},
+		{
+			IconCaption: "Участок",
+			Geometry: models.NewPolygonGeometry(
+				[][]float64{{37.6, 55.7}, {37.7, 55.7}, {37.7, 55.8}, {37.6, 55.8}, {37.6, 55.7}},
+				[][]float64{{37.62, 55.72}, {37.62, 55.74}, {37.64, 55.74}, {37.64, 55.72}, {37.62, 55.72}},
+			),
+		},
+		{
+			IconCaption: "Острова",
+			Geometry: models.NewMultiPolygonGeometry(
+				[][][]float64{{{37.6, 55.7}, {37.7, 55.7}, {37.7, 55.8}, {37.6, 55.7}}},
+				[][][]float64{{{38.6, 55.7}, {38.7, 55.7}, {38.7, 55.8}, {38.6, 55.7}}},
+			),
+		},
+		{
+			IconCaption: "Состав",
+			Geometry: models.NewCollectionGeometry(
+				models.NewPointGeometry(37.6, 55.7),
+				models.NewLineStringGeometry([]float64{37.6, 55.7}, []float64{37.7, 55.8}),
+			),
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "out.gpkg")
+	w := writer.NewGeoPackageWriter()
+	if err := w.Write(&items); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	r, err := NewGeoPackageReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	// Объекты раскладываются по слоям типов геометрии, поэтому сравниваются по названию
+	read := make(map[string]models.CordsData, len(*data))
+	for _, item := range *data {
+		read[item.IconCaption] = item
+	}
+	if len(read) != len(items) {
+		t.Fatalf("прочитано объектов: %d; ожидается %d", len(read), len(items))
+	}
+
+	for _, want := range items {
+		t.Run(want.IconCaption, func(t *testing.T) {
+			got, ok := read[want.IconCaption]
+			if !ok {
+				t.Fatalf("объект не прочитан")
+			}
+			if got.ID != want.ID || got.Description != want.Description {
+				t.Errorf("атрибуты = %q, %q; ожидается %q, %q", got.ID, got.Description, want.ID, want.Description)
+			}
+			if !reflect.DeepEqual(got.Geometry, want.Geometry) {
+				t.Errorf("геометрия = %#v; ожидается %#v", got.Geometry, want.Geometry)
+			}
+			for key, value := range want.Properties {
+				if got.Properties[key] != value {
+					t.Errorf("свойство %s = %#v; ожидается %#v", key, got.Properties[key], value)
+				}
+			}
+			for key, value := range want.Style {
+				if got.Style[key] != value {
+					t.Errorf("оформление %s = %#v; ожидается %#v", key, got.Style[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package gpx читает путевые точки, маршруты и треки из GPX (Garmin и другие навигаторы).
+//
+// Путевая точка wpt становится точкой: name - название, desc (или cmt) - описание,
+// высота ele - третьей координатой и свойством ele, время - свойством models.PropertyTime,
+// sym, type и cmt - свойствами. Маршрут rte и трек trk становятся линиями
+// (трек из нескольких сегментов - мультилинией), высота точек сохраняется в координатах,
+// а время точек - в свойстве models.PropertyCoordTimes
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// Свойства, в которые переносятся поля GPX без отдельного места в объекте
+const (
+	PropertyElevation = "ele"
+	PropertySymbol    = "sym"
+	PropertyType      = "type"
+	PropertyComment   = "cmt"
+	// PropertyKind вид объекта GPX: wpt, rte или trk
+	PropertyKind = "gpx_type"
+)
+
+// GPXReader читает объекты из файла .gpx
+type GPXReader struct {
+	path string
+}
+
+// NewGPXReader создаёт reader для файла GPX 1.0 или 1.1
+func NewGPXReader(path string) (*GPXReader, error) {
+	return &GPXReader{path: path}, nil
+}
+
+// Read читает путевые точки, затем маршруты и треки
+func (r *GPXReader) Read() (*[]models.CordsData, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать GPX файл: %w", err)
+	}
+
+	var doc gpxDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать GPX: %w", err)
+	}
+
+	var result []models.CordsData
+	for i, wpt := range doc.Waypoints {
+		pos, err := wpt.position()
+		if err != nil {
+			return nil, fmt.Errorf("путевая точка %d ('%s'): %w", i+1, wpt.Name, err)
+		}
+		item := wpt.gpxInfo.toCordsData("wpt")
+		item.Geometry = &models.PointGeometry{Coordinates: pos}
+		if len(pos) > 2 {
+			item.SetProperty(PropertyElevation, pos[2])
+		}
+		if when := strings.TrimSpace(wpt.Time); when != "" {
+			item.SetProperty(models.PropertyTime, when)
+		}
+		result = append(result, item)
+	}
+
+	for i, rte := range doc.Routes {
+		positions, times, err := trackPoints(rte.Points)
+		if err != nil {
+			return nil, fmt.Errorf("маршрут %d ('%s'): %w", i+1, rte.Name, err)
+		}
+		item := rte.gpxInfo.toCordsData("rte")
+		item.Geometry = models.NewLineStringGeometry(positions...)
+		setCoordTimes(&item, times, len(positions))
+		result = append(result, item)
+	}
+
+	for i, trk := range doc.Tracks {
+		var lines [][][]float64
+		var times []any
+		for _, segment := range trk.Segments {
+			positions, segmentTimes, err := trackPoints(segment.Points)
+			if err != nil {
+				return nil, fmt.Errorf("трек %d ('%s'): %w", i+1, trk.Name, err)
+			}
+			if len(positions) > 0 {
+				lines = append(lines, positions)
+				times = append(times, segmentTimes...)
+			}
+		}
+
+		item := trk.gpxInfo.toCordsData("trk")
+		switch len(lines) {
+		case 0:
+			item.Geometry = &models.LineStringGeometry{}
+		case 1:
+			item.Geometry = models.NewLineStringGeometry(lines[0]...)
+		default:
+			item.Geometry = models.NewMultiLineStringGeometry(lines...)
+		}
+		count := 0
+		for _, line := range lines {
+			count += len(line)
+		}
+		setCoordTimes(&item, times, count)
+		result = append(result, item)
+	}
+
+	return &result, nil
+}
+
+// Close ничего не делает: файл читается целиком в Read
+func (r *GPXReader) Close() error {
+	return nil
+}
+
+// toCordsData переносит общие поля элемента GPX в объект
+func (info gpxInfo) toCordsData(kind string) models.CordsData {
+	item := models.CordsData{
+		IconCaption: strings.TrimSpace(info.Name),
+		Description: strings.TrimSpace(info.Desc),
+		Notation:    models.NotationDecimal,
+	}
+	comment := strings.TrimSpace(info.Cmt)
+	switch {
+	case item.Description == "":
+		item.Description = comment
+	case comment != "" && comment != item.Description:
+		item.SetProperty(PropertyComment, comment)
+	}
+	if sym := strings.TrimSpace(info.Sym); sym != "" {
+		item.SetProperty(PropertySymbol, sym)
+	}
+	if typ := strings.TrimSpace(info.Type); typ != "" {
+		item.SetProperty(PropertyType, typ)
+	}
+	item.SetProperty(PropertyKind, kind)
+	return item
+}
+
+// position возвращает координату точки [долгота, широта(, высота)]
+func (p gpxPoint) position() ([]float64, error) {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(p.Lat), 64)
+	if err != nil {
+		return nil, fmt.Errorf("неверная широта '%s'", p.Lat)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(p.Lon), 64)
+	if err != nil {
+		return nil, fmt.Errorf("неверная долгота '%s'", p.Lon)
+	}
+	pos := []float64{lon, lat}
+	if ele := strings.TrimSpace(p.Ele); ele != "" {
+		alt, err := strconv.ParseFloat(ele, 64)
+		if err != nil {
+			return nil, fmt.Errorf("неверная высота '%s'", p.Ele)
+		}
+		pos = append(pos, alt)
+	}
+	return pos, nil
+}
+
+// trackPoints возвращает координаты точек маршрута или сегмента трека и их время
+func trackPoints(points []gpxPoint) ([][]float64, []any, error) {
+	positions := make([][]float64, 0, len(points))
+	times := make([]any, 0, len(points))
+	for i, point := range points {
+		pos, err := point.position()
+		if err != nil {
+			return nil, nil, fmt.Errorf("точка %d: %w", i+1, err)
+		}
+		positions = append(positions, pos)
+		times = append(times, strings.TrimSpace(point.Time))
+	}
+	return positions, times, nil
+}
+
+// setCoordTimes сохраняет время точек, если оно указано у каждой точки линии
+func setCoordTimes(item *models.CordsData, times []any, count int) {
+	if len(times) == 0 || len(times) != count {
+		return
+	}
+	for _, when := range times {
+		if when == "" {
+			return
+		}
+	}
+	item.SetProperty(models.PropertyCoordTimes, times)
+}
@@ -0,0 +1,42 @@
+package gpx
+
+// Структуры повторяют элементы GPX 1.0 и 1.1 в том объёме, который нужен для чтения объектов.
+// Теги указаны без пространства имён, поэтому обе версии читаются одинаково
+
+type gpxDocument struct {
+	Waypoints []gpxPoint `xml:"wpt"`
+	Routes    []gpxRoute `xml:"rte"`
+	Tracks    []gpxTrack `xml:"trk"`
+}
+
+// gpxInfo общие поля путевой точки, маршрута и трека
+type gpxInfo struct {
+	Name string `xml:"name"`
+	Cmt  string `xml:"cmt"`
+	Desc string `xml:"desc"`
+	Sym  string `xml:"sym"`
+	Type string `xml:"type"`
+}
+
+// gpxPoint путевая точка wpt, точка маршрута rtept или трека trkpt
+type gpxPoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Ele  string `xml:"ele"`
+	Time string `xml:"time"`
+	gpxInfo
+}
+
+type gpxRoute struct {
+	gpxInfo
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	gpxInfo
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
@@ -0,0 +1,443 @@
+// Package kml читает объекты из KML и KMZ (Google Earth, OruxMaps и т.п.).
+//
+// Placemark становится объектом: name - название, description - описание,
+// ExtendedData (Data и SchemaData) - свойства (числа - float64). Атрибут id - идентификатор,
+// в документах KMLWriter префикс kmlid.Prefix убирается. Объекты читаются в порядке документа.
+// Путь папок Folder записывается в свойство models.PropertyFolder, время TimeStamp - в models.PropertyTime.
+// Стили Style и StyleMap переводятся в оформление simplestyle (marker-color, stroke, fill и т.д.).
+// gx:Track читается как линия, время точек сохраняется в models.PropertyCoordTimes
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/kmlid"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// KMLReader читает объекты из файла .kml или .kmz
+type KMLReader struct {
+	path string
+}
+
+// NewKMLReader создаёт reader для файла .kml или .kmz (архив с документом KML)
+func NewKMLReader(path string) (*KMLReader, error) {
+	return &KMLReader{path: path}, nil
+}
+
+// Read читает все Placemark документа, включая вложенные в Document и Folder
+func (r *KMLReader) Read() (*[]models.CordsData, error) {
+	data, err := readDocument(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root kmlContainer
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать KML: %w", err)
+	}
+
+	styles := make(map[string]*kmlStyle)
+	styleMaps := make(map[string]string)
+	collectStyles(&root, styles, styleMaps)
+
+	decodeIDs := hasIDMarker(&root)
+
+	var result []models.CordsData
+	var walk func(c *kmlContainer, folder string) error
+	walk = func(c *kmlContainer, folder string) error {
+		for _, child := range c.Children {
+			var err error
+			switch child.kind {
+			case "Placemark":
+				placemark := &c.Placemarks[child.index]
+				item, itemErr := placemark.toCordsData(styles, styleMaps, decodeIDs)
+				if itemErr != nil {
+					return fmt.Errorf("объект %d ('%s'): %w", len(result)+1, placemark.Name, itemErr)
+				}
+				if folder != "" {
+					item.SetProperty(models.PropertyFolder, folder)
+				}
+				result = append(result, item)
+			case "Folder":
+				err = walk(&c.Folders[child.index], joinFolder(folder, c.Folders[child.index].Name))
+			case "Document":
+				// Document не добавляет уровень папок: это корень файла
+				err = walk(&c.Documents[child.index], folder)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(&root, ""); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Close ничего не делает: файл читается целиком в Read
+func (r *KMLReader) Close() error {
+	return nil
+}
+
+// readDocument возвращает содержимое KML: из файла .kml или из архива .kmz
+// (doc.kml, а при его отсутствии - первый файл .kml архива)
+func readDocument(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать KML файл: %w", err)
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".kmz") {
+		return data, nil
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть архив KMZ: %w", err)
+	}
+	var document *zip.File
+	for _, file := range archive.File {
+		if !strings.EqualFold(filepath.Ext(file.Name), ".kml") {
+			continue
+		}
+		if document == nil || strings.EqualFold(file.Name, "doc.kml") {
+			document = file
+		}
+	}
+	if document == nil {
+		return nil, fmt.Errorf("в архиве KMZ нет документа .kml")
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть %s в архиве KMZ: %w", document.Name, err)
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// joinFolder добавляет папку к пути; папка без названия не добавляет уровень
+func joinFolder(path, name string) string {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return path
+	case path == "":
+		return name
+	}
+	return path + "/" + name
+}
+
+// collectStyles собирает общие стили документа по идентификатору
+// и пары StyleMap (идентификатор -> ссылка на стиль normal)
+func collectStyles(c *kmlContainer, styles map[string]*kmlStyle, styleMaps map[string]string) {
+	for i := range c.Styles {
+		if id := c.Styles[i].ID; id != "" {
+			styles[id] = &c.Styles[i]
+		}
+	}
+	for _, styleMap := range c.StyleMaps {
+		for _, pair := range styleMap.Pairs {
+			if pair.Key == "normal" {
+				styleMaps[styleMap.ID] = pair.StyleURL
+			}
+		}
+	}
+	for i := range c.Folders {
+		collectStyles(&c.Folders[i], styles, styleMaps)
+	}
+	for i := range c.Documents {
+		collectStyles(&c.Documents[i], styles, styleMaps)
+	}
+}
+
+// toCordsData преобразует Placemark в объект; decodeIDs - убрать префикс идентификатора,
+// добавленный KMLWriter
+func (p *kmlPlacemark) toCordsData(styles map[string]*kmlStyle, styleMaps map[string]string, decodeIDs bool) (models.CordsData, error) {
+	id := strings.TrimSpace(p.ID)
+	if decodeIDs {
+		id = kmlid.Decode(id)
+	}
+	item := models.CordsData{
+		ID:          id,
+		IconCaption: strings.TrimSpace(p.Name),
+		Description: strings.TrimSpace(p.Description),
+		Notation:    models.NotationDecimal,
+	}
+
+	geometries, times, err := p.kmlGeometries.toGeometries()
+	if err != nil {
+		return item, err
+	}
+	item.Geometry = combineGeometries(geometries)
+	if len(times) > 0 {
+		item.SetProperty(models.PropertyCoordTimes, times)
+	}
+	if when := strings.TrimSpace(p.TimeStamp.When); when != "" {
+		item.SetProperty(models.PropertyTime, when)
+	}
+
+	for _, data := range p.ExtendedData.Data {
+		item.SetProperty(data.Name, propertyValue(data.Value))
+	}
+	for _, schema := range p.ExtendedData.SchemaData {
+		for _, data := range schema.SimpleData {
+			item.SetProperty(data.Name, propertyValue(data.Value))
+		}
+	}
+
+	// Встроенный стиль дополняет стиль по ссылке
+	if style := resolveStyle(p.StyleURL, styles, styleMaps); style != nil {
+		style.apply(&item)
+	}
+	if p.Style != nil {
+		p.Style.apply(&item)
+	}
+	return item, nil
+}
+
+// propertyValue возвращает значение ExtendedData: число в обычной записи (как его записывает
+// KMLWriter) становится float64, как в GeoJSON, остальное - текстом. Значения с ведущими нулями,
+// знаком + или экспонентой остаются текстом, чтобы не потерять коды и номера
+func propertyValue(value string) any {
+	text := strings.TrimSpace(value)
+	if num, err := strconv.ParseFloat(text, 64); err == nil && strconv.FormatFloat(num, 'f', -1, 64) == text {
+		return num
+	}
+	return text
+}
+
+// hasIDMarker сообщает, что документ записан KMLWriter с префиксом идентификаторов (kmlid.Marker)
+func hasIDMarker(c *kmlContainer) bool {
+	for _, data := range c.ExtendedData.Data {
+		if data.Name == kmlid.Marker && strings.TrimSpace(data.Value) == kmlid.Prefix {
+			return true
+		}
+	}
+	for i := range c.Documents {
+		if hasIDMarker(&c.Documents[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveStyle находит стиль по ссылке styleUrl ("#id"), в том числе через StyleMap
+func resolveStyle(url string, styles map[string]*kmlStyle, styleMaps map[string]string) *kmlStyle {
+	id := strings.TrimPrefix(strings.TrimSpace(url), "#")
+	if normal, ok := styleMaps[id]; ok {
+		id = strings.TrimPrefix(strings.TrimSpace(normal), "#")
+	}
+	return styles[id]
+}
+
+// apply переводит стиль KML в оформление simplestyle для типа геометрии объекта
+func (s *kmlStyle) apply(item *models.CordsData) {
+	set := func(key string, value any) {
+		if models.StyleAppliesTo(key, item.GeometryType()) {
+			item.Style.Set(key, value)
+		}
+	}
+
+	if s.IconStyle != nil {
+		if color, _, ok := parseColor(s.IconStyle.Color); ok {
+			set(models.StyleMarkerColor, color)
+		}
+	}
+	if s.LineStyle != nil {
+		if color, opacity, ok := parseColor(s.LineStyle.Color); ok {
+			set(models.StyleStroke, color)
+			if opacity < 1 {
+				set(models.StyleStrokeOpacity, opacity)
+			}
+		}
+		if s.LineStyle.Width > 0 {
+			set(models.StyleStrokeWidth, s.LineStyle.Width)
+		}
+	}
+	if s.PolyStyle != nil {
+		if color, opacity, ok := parseColor(s.PolyStyle.Color); ok {
+			set(models.StyleFill, color)
+			if opacity < 1 {
+				set(models.StyleFillOpacity, opacity)
+			}
+		}
+		if s.PolyStyle.Fill != nil && strings.TrimSpace(*s.PolyStyle.Fill) == "0" {
+			set(models.StyleFillOpacity, 0.0)
+		}
+	}
+}
+
+// parseColor переводит цвет KML aabbggrr в #RRGGBB и непрозрачность от 0 до 1
+func parseColor(value string) (string, float64, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(value) != 8 {
+		return "", 0, false
+	}
+	v, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return "", 0, false
+	}
+	a, b, g, r := v>>24, (v>>16)&0xFF, (v>>8)&0xFF, v&0xFF
+	opacity := float64(int(float64(a)/255*100+0.5)) / 100
+	return fmt.Sprintf("#%02X%02X%02X", r, g, b), opacity, true
+}
+
+// toGeometries собирает геометрии элемента; для gx:Track возвращается также время точек
+func (g *kmlGeometries) toGeometries() ([]models.Geometry, []any, error) {
+	var result []models.Geometry
+	var times []any
+
+	for _, point := range g.Points {
+		positions, err := parseCoordinates(point.Coordinates)
+		if err != nil {
+			return nil, nil, fmt.Errorf("точка: %w", err)
+		}
+		if len(positions) != 1 {
+			return nil, nil, fmt.Errorf("точка: ожидается одна координата, найдено %d", len(positions))
+		}
+		result = append(result, models.NewPointGeometry(positions[0][0], positions[0][1], positions[0][2:]...))
+	}
+	for _, line := range append(g.LineStrings, g.LinearRings...) {
+		positions, err := parseCoordinates(line.Coordinates)
+		if err != nil {
+			return nil, nil, fmt.Errorf("линия: %w", err)
+		}
+		result = append(result, models.NewLineStringGeometry(positions...))
+	}
+	for _, polygon := range g.Polygons {
+		outer, err := parseCoordinates(polygon.Outer.Ring.Coordinates)
+		if err != nil {
+			return nil, nil, fmt.Errorf("полигон: %w", err)
+		}
+		rings := [][][]float64{outer}
+		for _, inner := range polygon.Inner {
+			ring, err := parseCoordinates(inner.Ring.Coordinates)
+			if err != nil {
+				return nil, nil, fmt.Errorf("полигон: %w", err)
+			}
+			rings = append(rings, ring)
+		}
+		result = append(result, models.NewPolygonGeometry(rings...))
+	}
+
+	tracks := g.Tracks
+	for _, multi := range g.MultiTracks {
+		tracks = append(tracks, multi.Tracks...)
+	}
+	for _, track := range tracks {
+		var positions [][]float64
+		for _, coord := range track.Coords {
+			pos, err := parseTuple(strings.Fields(coord), " ")
+			if err != nil {
+				return nil, nil, fmt.Errorf("трек: %w", err)
+			}
+			positions = append(positions, pos)
+		}
+		result = append(result, models.NewLineStringGeometry(dropZeroAltitude(positions)...))
+		for _, when := range track.When {
+			times = append(times, strings.TrimSpace(when))
+		}
+	}
+
+	for i := range g.Multi {
+		members, memberTimes, err := g.Multi[i].toGeometries()
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, members...)
+		times = append(times, memberTimes...)
+	}
+	return result, times, nil
+}
+
+// combineGeometries объединяет геометрии Placemark: одна геометрия возвращается как есть,
+// однотипные точки, линии и полигоны - мультигеометрией, разнотипные - коллекцией
+func combineGeometries(geometries []models.Geometry) models.Geometry {
+	switch len(geometries) {
+	case 0:
+		return nil
+	case 1:
+		return geometries[0]
+	}
+
+	var points [][]float64
+	var lines [][][]float64
+	var polygons [][][][]float64
+	for _, geometry := range geometries {
+		switch g := geometry.(type) {
+		case *models.PointGeometry:
+			points = append(points, g.Coordinates)
+		case *models.LineStringGeometry:
+			lines = append(lines, g.Coordinates)
+		case *models.PolygonGeometry:
+			polygons = append(polygons, g.Coordinates)
+		}
+	}
+	switch len(geometries) {
+	case len(points):
+		return models.NewMultiPointGeometry(points...)
+	case len(lines):
+		return models.NewMultiLineStringGeometry(lines...)
+	case len(polygons):
+		return models.NewMultiPolygonGeometry(polygons...)
+	}
+	return models.NewCollectionGeometry(geometries...)
+}
+
+// parseCoordinates разбирает список координат KML "долгота,широта[,высота] ..."
+func parseCoordinates(text string) ([][]float64, error) {
+	var positions [][]float64
+	for _, tuple := range strings.Fields(text) {
+		pos, err := parseTuple(strings.Split(tuple, ","), ",")
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, pos)
+	}
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("не указаны координаты")
+	}
+	return dropZeroAltitude(positions), nil
+}
+
+// parseTuple разбирает одну координату из частей "долгота", "широта" и необязательной высоты
+func parseTuple(parts []string, sep string) ([]float64, error) {
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("неверная координата '%s'", strings.Join(parts, sep))
+	}
+	pos := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("неверная координата '%s'", strings.Join(parts, sep))
+		}
+		pos = append(pos, v)
+	}
+	return pos, nil
+}
+
+// dropZeroAltitude убирает высоту, если у всех координат она нулевая:
+// Google Earth записывает 0 для объектов, привязанных к земле
+func dropZeroAltitude(positions [][]float64) [][]float64 {
+	for _, pos := range positions {
+		if len(pos) > 2 && pos[2] != 0 {
+			return positions
+		}
+	}
+	for i, pos := range positions {
+		positions[i] = pos[:2]
+	}
+	return positions
+}
@@ -0,0 +1,169 @@
+package kml
+
+import "encoding/xml"
+
+// Структуры повторяют элементы KML 2.2 в том объёме, который нужен для чтения объектов.
+// Теги указаны без пространства имён, поэтому gx:Track и gx:coord читаются так же, как элементы kml
+
+// kmlContainer корень файла, Document или Folder
+type kmlContainer struct {
+	Name         string
+	Documents    []kmlContainer
+	Folders      []kmlContainer
+	Placemarks   []kmlPlacemark
+	Styles       []kmlStyle
+	StyleMaps    []kmlStyleMap
+	ExtendedData kmlExtendedData
+	// Children Placemark, Folder и Document в порядке документа
+	Children []kmlChild
+}
+
+// kmlChild ссылка на вложенный элемент контейнера: вид элемента и номер в его срезе
+type kmlChild struct {
+	kind  string
+	index int
+}
+
+// UnmarshalXML разбирает контейнер по элементам, запоминая порядок Placemark, Folder и Document:
+// стандартный разбор раскладывает их по срезам и порядок между ними теряется
+func (c *kmlContainer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			var err error
+			switch t.Name.Local {
+			case "name":
+				err = d.DecodeElement(&c.Name, &t)
+			case "Document":
+				var child kmlContainer
+				err = d.DecodeElement(&child, &t)
+				c.Children = append(c.Children, kmlChild{kind: t.Name.Local, index: len(c.Documents)})
+				c.Documents = append(c.Documents, child)
+			case "Folder":
+				var child kmlContainer
+				err = d.DecodeElement(&child, &t)
+				c.Children = append(c.Children, kmlChild{kind: t.Name.Local, index: len(c.Folders)})
+				c.Folders = append(c.Folders, child)
+			case "Placemark":
+				var placemark kmlPlacemark
+				err = d.DecodeElement(&placemark, &t)
+				c.Children = append(c.Children, kmlChild{kind: t.Name.Local, index: len(c.Placemarks)})
+				c.Placemarks = append(c.Placemarks, placemark)
+			case "Style":
+				var style kmlStyle
+				err = d.DecodeElement(&style, &t)
+				c.Styles = append(c.Styles, style)
+			case "StyleMap":
+				var styleMap kmlStyleMap
+				err = d.DecodeElement(&styleMap, &t)
+				c.StyleMaps = append(c.StyleMaps, styleMap)
+			case "ExtendedData":
+				err = d.DecodeElement(&c.ExtendedData, &t)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type kmlPlacemark struct {
+	ID           string          `xml:"id,attr"`
+	Name         string          `xml:"name"`
+	Description  string          `xml:"description"`
+	StyleURL     string          `xml:"styleUrl"`
+	Style        *kmlStyle       `xml:"Style"`
+	TimeStamp    kmlTimeStamp    `xml:"TimeStamp"`
+	ExtendedData kmlExtendedData `xml:"ExtendedData"`
+	kmlGeometries
+}
+
+// kmlGeometries геометрии Placemark или MultiGeometry
+type kmlGeometries struct {
+	Points      []kmlCoordinates `xml:"Point"`
+	LineStrings []kmlCoordinates `xml:"LineString"`
+	LinearRings []kmlCoordinates `xml:"LinearRing"`
+	Polygons    []kmlPolygon     `xml:"Polygon"`
+	Multi       []kmlGeometries  `xml:"MultiGeometry"`
+	Tracks      []kmlTrack       `xml:"Track"`
+	MultiTracks []kmlMultiTrack  `xml:"MultiTrack"`
+}
+
+type kmlCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlPolygon struct {
+	Outer kmlBoundary   `xml:"outerBoundaryIs"`
+	Inner []kmlBoundary `xml:"innerBoundaryIs"`
+}
+
+type kmlBoundary struct {
+	Ring kmlCoordinates `xml:"LinearRing"`
+}
+
+// kmlTrack gx:Track: время when и координаты gx:coord "долгота широта высота"
+type kmlTrack struct {
+	When   []string `xml:"when"`
+	Coords []string `xml:"coord"`
+}
+
+type kmlMultiTrack struct {
+	Tracks []kmlTrack `xml:"Track"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlExtendedData struct {
+	Data       []kmlData       `xml:"Data"`
+	SchemaData []kmlSchemaData `xml:"SchemaData"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlSchemaData struct {
+	SimpleData []kmlSimpleData `xml:"SimpleData"`
+}
+
+type kmlSimpleData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type kmlStyle struct {
+	ID        string         `xml:"id,attr"`
+	IconStyle *kmlColorStyle `xml:"IconStyle"`
+	LineStyle *kmlColorStyle `xml:"LineStyle"`
+	PolyStyle *kmlColorStyle `xml:"PolyStyle"`
+}
+
+// kmlColorStyle IconStyle, LineStyle или PolyStyle
+type kmlColorStyle struct {
+	Color string  `xml:"color"`
+	Width float64 `xml:"width"`
+	// Fill 0 - полигон без заливки (PolyStyle)
+	Fill *string `xml:"fill"`
+}
+
+type kmlStyleMap struct {
+	ID    string         `xml:"id,attr"`
+	Pairs []kmlStylePair `xml:"Pair"`
+}
+
+type kmlStylePair struct {
+	Key      string `xml:"key"`
+	StyleURL string `xml:"styleUrl"`
+}
@@ -9,3 +9,11 @@ type Reader interface {
 	// Close закрывает соединение с источником
 	Close() error
 }
+
+// TableReader читает строки таблицы без координат (ключ в ID и свойства)
+type TableReader interface {
+	// ReadTable читает строки таблицы
+	ReadTable() (*[]models.CordsData, error)
+	// Close закрывает соединение с источником
+	Close() error
+}
@@ -0,0 +1,155 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// dbfTable атрибуты шейп-файла: записи по порядку фигур и кодировка текстовых полей.
+// Удалённая запись (флаг '*') - nil
+type dbfTable struct {
+	records  []map[string]any
+	encoding string
+}
+
+// dbfField описание поля .dbf
+type dbfField struct {
+	name     string
+	kind     byte
+	length   int
+	decimals int
+}
+
+// parseDBF разбирает таблицу атрибутов dBase. Кодировка берётся из cpg (содержимое .cpg),
+// затем из кода языка в заголовке, а если он не задан - определяется по содержимому
+func parseDBF(data []byte, cpg string) (*dbfTable, error) {
+	if len(data) < 32 {
+		return nil, fmt.Errorf("файл .dbf повреждён")
+	}
+	count := int(binary.LittleEndian.Uint32(data[4:8]))
+	headerLen := int(binary.LittleEndian.Uint16(data[8:10]))
+	recordLen := int(binary.LittleEndian.Uint16(data[10:12]))
+	if headerLen > len(data) || recordLen < 1 {
+		return nil, fmt.Errorf("файл .dbf повреждён: неверный заголовок")
+	}
+
+	enc, title := dbfEncoding(data, cpg, headerLen, recordLen, count)
+	decoder := enc.NewDecoder()
+
+	var fields []dbfField
+	for offset := 32; offset+32 <= headerLen && data[offset] != 0x0D; offset += 32 {
+		desc := data[offset : offset+32]
+		name, _, _ := bytes.Cut(desc[:11], []byte{0})
+		if decoded, err := decoder.Bytes(name); err == nil {
+			name = decoded
+		}
+		fields = append(fields, dbfField{
+			name:     strings.TrimSpace(string(name)),
+			kind:     desc[11],
+			length:   int(desc[16]),
+			decimals: int(desc[17]),
+		})
+	}
+
+	// Поля записи и флаг удаления должны помещаться в длину записи из заголовка
+	width := 1
+	for _, field := range fields {
+		width += field.length
+	}
+	if width > recordLen {
+		return nil, fmt.Errorf("файл .dbf повреждён: поля занимают %d байт, а длина записи - %d", width, recordLen)
+	}
+
+	table := &dbfTable{encoding: title}
+	for i := 0; i < count; i++ {
+		start := headerLen + i*recordLen
+		if start+recordLen > len(data) {
+			return nil, fmt.Errorf("файл .dbf обрезан: запись %d из %d", i+1, count)
+		}
+		if data[start] == '*' {
+			table.records = append(table.records, nil)
+			continue
+		}
+		record := make(map[string]any, len(fields))
+		pos := start + 1 // флаг удаления
+		for _, field := range fields {
+			raw := data[pos : pos+field.length]
+			pos += field.length
+			text, err := decoder.Bytes(raw)
+			if err != nil {
+				text = raw
+			}
+			record[field.name] = fieldValue(field, strings.TrimSpace(strings.TrimRight(string(text), "\x00")))
+		}
+		table.records = append(table.records, record)
+	}
+	return table, nil
+}
+
+// fieldValue приводит текст поля к значению свойства: числа - float64, логические - bool,
+// даты - "ГГГГ-ММ-ДД"; пустое значение - nil
+func fieldValue(field dbfField, text string) any {
+	if text == "" {
+		return nil
+	}
+	switch field.kind {
+	case 'N', 'F':
+		if num, err := strconv.ParseFloat(text, 64); err == nil {
+			return num
+		}
+		if strings.Trim(text, "*") == "" {
+			return nil
+		}
+	case 'L':
+		switch strings.ToUpper(text) {
+		case "T", "Y", "Д":
+			return true
+		case "F", "N", "Н":
+			return false
+		}
+		return nil
+	case 'D':
+		if len(text) == 8 {
+			return text[0:4] + "-" + text[4:6] + "-" + text[6:8]
+		}
+	}
+	return text
+}
+
+// dbfEncoding определяет кодировку текстовых полей и её название
+func dbfEncoding(data []byte, cpg string, headerLen, recordLen, count int) (encoding.Encoding, string) {
+	switch page := strings.ToUpper(strings.TrimSpace(cpg)); {
+	case page == "":
+	case strings.Contains(page, "UTF"):
+		return unicode.UTF8, "UTF-8 (.cpg)"
+	case strings.Contains(page, "1251"):
+		return charmap.Windows1251, "Windows-1251 (.cpg)"
+	case strings.Contains(page, "866"):
+		return charmap.CodePage866, "CP866 (.cpg)"
+	case strings.Contains(page, "KOI8"):
+		return charmap.KOI8R, "KOI8-R (.cpg)"
+	}
+
+	// Код языка (Language Driver ID) в байте 29 заголовка
+	switch data[29] {
+	case 0xC9:
+		return charmap.Windows1251, "Windows-1251"
+	case 0x26, 0x65:
+		return charmap.CodePage866, "CP866"
+	}
+
+	// Текст без кодировки: UTF-8, если все текстовые поля читаются как UTF-8, иначе Windows-1251
+	end := min(headerLen+count*recordLen, len(data))
+	if utf8.Valid(data[headerLen:end]) {
+		return unicode.UTF8, "UTF-8"
+	}
+	return charmap.Windows1251, "Windows-1251 (по содержимому)"
+}
@@ -0,0 +1,261 @@
+// Package shapefile читает объекты из шейп-файлов ESRI (.shp с .dbf, .prj и .cpg) или zip-архива с ними.
+//
+// Геометрия .shp становится геометрией объекта (высота Z - третьей координатой, M отбрасывается),
+// атрибуты .dbf - свойствами, а поля name, descr (description) и id - названием, описанием
+// и идентификатором. Кодировка атрибутов берётся из .cpg или кода языка в заголовке .dbf,
+// иначе определяется по содержимому. Координаты системы из .prj переводятся в WGS 84.
+// В архиве читаются все шейп-файлы в порядке их записи
+package shapefile
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/crs"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// ShapefileReader читает объекты из .shp или .zip
+type ShapefileReader struct {
+	path string
+}
+
+// NewShapefileReader создаёт reader для шейп-файла или zip-архива с шейп-файлами
+func NewShapefileReader(path string) (*ShapefileReader, error) {
+	return &ShapefileReader{path: path}, nil
+}
+
+// layer файлы одного шейп-файла: содержимое .shp, .dbf, .prj и .cpg (отсутствующие - nil)
+type layer struct {
+	name string
+	shp  []byte
+	dbf  []byte
+	prj  []byte
+	cpg  []byte
+}
+
+// Read читает все объекты шейп-файла (или всех шейп-файлов архива)
+func (r *ShapefileReader) Read() (*[]models.CordsData, error) {
+	var layers []layer
+	var err error
+	if strings.EqualFold(filepath.Ext(r.path), ".zip") {
+		layers, err = readArchive(r.path)
+	} else {
+		layers, err = readFiles(r.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.CordsData{}
+	for _, l := range layers {
+		items, err := l.read()
+		if err != nil {
+			return nil, fmt.Errorf("шейп-файл %s: %w", l.name, err)
+		}
+		result = append(result, items...)
+	}
+	return &result, nil
+}
+
+// Close ничего не делает: файлы читаются целиком в Read
+func (r *ShapefileReader) Close() error {
+	return nil
+}
+
+// readFiles читает .shp и файлы рядом с ним с тем же именем
+func readFiles(path string) ([]layer, error) {
+	shp, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать шейп-файл: %w", err)
+	}
+	l := layer{name: filepath.Base(path), shp: shp}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		name := filepath.Join(filepath.Dir(path), entry.Name())
+		ext := filepath.Ext(name)
+		if entry.IsDir() || !strings.EqualFold(strings.TrimSuffix(name, ext), base) {
+			continue
+		}
+		if target := l.file(ext); target != nil {
+			if *target, err = os.ReadFile(name); err != nil {
+				return nil, fmt.Errorf("не удалось прочитать %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return []layer{l}, nil
+}
+
+// readArchive читает все шейп-файлы zip-архива (в том числе из вложенных папок)
+func readArchive(path string) ([]layer, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть архив: %w", err)
+	}
+	defer archive.Close()
+
+	layers := make(map[string]*layer)
+	var order []string
+	for _, file := range archive.File {
+		// Служебные файлы macOS (__MACOSX/, ._имя) повторяют имена шейп-файлов, но это не шейп-файлы
+		if isMacMetadata(file.Name) {
+			continue
+		}
+		ext := filepath.Ext(file.Name)
+		key := strings.ToLower(strings.TrimSuffix(file.Name, ext))
+		l, ok := layers[key]
+		if !ok {
+			l = &layer{}
+			layers[key] = l
+			order = append(order, key)
+		}
+		target := l.file(ext)
+		if target == nil {
+			continue
+		}
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать %s из архива: %w", file.Name, err)
+		}
+		*target = data
+		if strings.EqualFold(ext, ".shp") {
+			l.name = file.Name
+		}
+	}
+
+	var result []layer
+	for _, key := range order {
+		if l := layers[key]; l.shp != nil {
+			result = append(result, *l)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("в архиве %s нет шейп-файлов (.shp)", filepath.Base(path))
+	}
+	return result, nil
+}
+
+// isMacMetadata сообщает, что файл архива - служебные данные Finder: папка __MACOSX или файл ._имя
+func isMacMetadata(name string) bool {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for _, part := range strings.Split(name, "/") {
+		if part == "__MACOSX" {
+			return true
+		}
+	}
+	return strings.HasPrefix(path.Base(name), "._")
+}
+
+// readZipFile возвращает содержимое файла архива
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// file возвращает поле для файла с расширением ext; nil - файл не нужен
+func (l *layer) file(ext string) *[]byte {
+	switch strings.ToLower(ext) {
+	case ".shp":
+		return &l.shp
+	case ".dbf":
+		return &l.dbf
+	case ".prj":
+		return &l.prj
+	case ".cpg":
+		return &l.cpg
+	}
+	return nil
+}
+
+// read разбирает геометрию и атрибуты шейп-файла и переводит координаты в WGS 84
+func (l layer) read() ([]models.CordsData, error) {
+	geometries, err := parseSHP(l.shp)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]any
+	if l.dbf != nil {
+		table, err := parseDBF(l.dbf, string(l.cpg))
+		if err != nil {
+			return nil, err
+		}
+		records = table.records
+		fmt.Printf("🔤 %s: кодировка атрибутов %s\n", l.name, table.encoding)
+	}
+
+	system := crs.WGS84CRS
+	if l.prj != nil {
+		if system, err = crs.ParsePRJ(string(l.prj)); err != nil {
+			return nil, fmt.Errorf("файл .prj: %w", err)
+		}
+		if !system.IsWGS84() {
+			fmt.Printf("🌐 %s: координаты переводятся из %s в WGS 84\n", l.name, system)
+		}
+	} else {
+		fmt.Printf("⚠️  %s: нет файла .prj, координаты считаются градусами WGS 84\n", l.name)
+	}
+
+	result := make([]models.CordsData, 0, len(geometries))
+	deleted := 0
+	for i, geometry := range geometries {
+		// Запись, удалённая в .dbf, удаляет и фигуру: .shp при удалении не переписывается
+		if i < len(records) && records[i] == nil {
+			deleted++
+			continue
+		}
+		item := models.CordsData{Geometry: geometry, Notation: models.NotationDecimal}
+		if geometry != nil && !system.IsWGS84() {
+			if item.Geometry, err = system.GeometryToWGS84(geometry); err != nil {
+				return nil, fmt.Errorf("объект %d: %w", i+1, err)
+			}
+		}
+		if i < len(records) {
+			setAttributes(&item, records[i])
+		}
+		result = append(result, item)
+	}
+	if deleted > 0 {
+		fmt.Printf("⚠️  %s: пропущено объектов, удалённых в .dbf: %d\n", l.name, deleted)
+	}
+	return result, nil
+}
+
+// setAttributes переносит атрибуты записи .dbf: name, descr (description) и id - в поля объекта,
+// остальные - в свойства
+func setAttributes(item *models.CordsData, record map[string]any) {
+	for key, value := range record {
+		text, isText := value.(string)
+		switch strings.ToLower(key) {
+		case "name":
+			if isText {
+				item.IconCaption = text
+				continue
+			}
+		case "descr", "description":
+			if isText {
+				item.Description = text
+				continue
+			}
+		case "id":
+			if value != nil {
+				item.ID = fmt.Sprint(value)
+				continue
+			}
+		}
+		if value != nil {
+			item.SetProperty(key, value)
+		}
+	}
+}
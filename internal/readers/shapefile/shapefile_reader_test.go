@@ -0,0 +1,103 @@
+package shapefile
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	writer "github.com/rmay1er/jgeo-excel/internal/writers/shapefile"
+)
+
+// TestWriteRead записывает объекты ShapefileWriter и читает их обратно
+func TestWriteRead(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		item models.CordsData
+	}{
+		{"точка", "points.shp", models.CordsData{
+			ID:          "7",
+			IconCaption: "Колодец",
+			Description: "у дороги",
+			Geometry:    models.NewPointGeometry(37.6173, 55.7558),
+			Properties:  map[string]any{"depth": 12.5, "kind": "бетон"},
+		}},
+		{"точка с высотой", "points_z.shp", models.CordsData{
+			IconCaption: "Опора",
+			Geometry:    models.NewPointGeometry(37.6173, 55.7558, 150),
+		}},
+		{"линия", "lines.shp", models.CordsData{
+			IconCaption: "Трасса",
+			Geometry:    models.NewLineStringGeometry([]float64{37.61, 55.75}, []float64{37.62, 55.76}, []float64{37.63, 55.75}),
+		}},
+		{"полигон с отверстием", "polygons.zip", models.CordsData{
+			IconCaption: "Участок",
+			Geometry: models.NewPolygonGeometry(
+				[][]float64{{37.6, 55.7}, {37.7, 55.7}, {37.7, 55.8}, {37.6, 55.8}, {37.6, 55.7}},
+				[][]float64{{37.62, 55.72}, {37.62, 55.74}, {37.64, 55.74}, {37.64, 55.72}, {37.62, 55.72}},
+			),
+		}},
+		{"мультиполигон", "multi.shp", models.CordsData{
+			IconCaption: "Острова",
+			Geometry: models.NewMultiPolygonGeometry(
+				[][][]float64{{{37.6, 55.7}, {37.7, 55.7}, {37.7, 55.8}, {37.6, 55.7}}},
+				[][][]float64{{{38.6, 55.7}, {38.7, 55.7}, {38.7, 55.8}, {38.6, 55.7}}},
+			),
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			w := writer.NewShapefileWriter()
+			if err := w.Write(&[]models.CordsData{tc.item}); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if err := w.Save(path); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			r, err := NewShapefileReader(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := r.Read()
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if len(*data) != 1 {
+				t.Fatalf("прочитано объектов: %d; ожидается 1", len(*data))
+			}
+			got := (*data)[0]
+			if got.ID != tc.item.ID || got.IconCaption != tc.item.IconCaption || got.Description != tc.item.Description {
+				t.Errorf("атрибуты = %q, %q, %q; ожидается %q, %q, %q",
+					got.ID, got.IconCaption, got.Description, tc.item.ID, tc.item.IconCaption, tc.item.Description)
+			}
+			if !reflect.DeepEqual(got.Geometry, tc.item.Geometry) {
+				t.Errorf("геометрия = %#v; ожидается %#v", got.Geometry, tc.item.Geometry)
+			}
+			for key, want := range tc.item.Properties {
+				if value := got.Properties[key]; value != want {
+					t.Errorf("свойство %s = %#v; ожидается %#v", key, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestIsMacMetadata(t *testing.T) {
+	tests := map[string]bool{
+		"out_points.shp":                   false,
+		"data/out_points.shp":              false,
+		"__MACOSX/._out_points.shp":        true,
+		"__MACOSX/data/._out_points.dbf":   true,
+		"._out_points.shp":                 true,
+		"data/._out_points.shx":            true,
+		"data\\__MACOSX\\._out_points.shp": true,
+	}
+	for name, want := range tests {
+		if got := isMacMetadata(name); got != want {
+			t.Errorf("isMacMetadata(%q) = %v; ожидается %v", name, got, want)
+		}
+	}
+}
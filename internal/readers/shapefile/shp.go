@@ -0,0 +1,250 @@
+package shapefile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// Типы фигур .shp
+const (
+	shapeNull        = 0
+	shapePoint       = 1
+	shapePolyLine    = 3
+	shapePolygon     = 5
+	shapeMultiPoint  = 8
+	shapePointZ      = 11
+	shapePolyLineZ   = 13
+	shapePolygonZ    = 15
+	shapeMultiPointZ = 18
+	shapePointM      = 21
+	shapePolyLineM   = 23
+	shapePolygonM    = 25
+	shapeMultiPointM = 28
+)
+
+// shpHeaderSize размер заголовка .shp и .shx
+const shpHeaderSize = 100
+
+// parseSHP разбирает записи .shp; фигура Null становится nil
+func parseSHP(data []byte) ([]models.Geometry, error) {
+	if len(data) < shpHeaderSize || binary.BigEndian.Uint32(data[0:4]) != 9994 {
+		return nil, fmt.Errorf("файл .shp повреждён или имеет неизвестный формат")
+	}
+
+	var result []models.Geometry
+	for offset := shpHeaderSize; offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset+4:offset+8])) * 2
+		start := offset + 8
+		if length < 4 || start+length > len(data) {
+			return nil, fmt.Errorf("запись %d обрезана", len(result)+1)
+		}
+		geometry, err := parseShape(data[start : start+length])
+		if err != nil {
+			return nil, fmt.Errorf("запись %d: %w", len(result)+1, err)
+		}
+		result = append(result, geometry)
+		offset = start + length
+	}
+	return result, nil
+}
+
+// shapeReader читает числа записи по порядку (little-endian)
+type shapeReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *shapeReader) int32() int {
+	if r.err != nil || r.pos+4 > len(r.data) {
+		r.err = fmt.Errorf("запись короче, чем указано в её заголовке")
+		return 0
+	}
+	v := int32(binary.LittleEndian.Uint32(r.data[r.pos:]))
+	r.pos += 4
+	return int(v)
+}
+
+func (r *shapeReader) float64() float64 {
+	if r.err != nil || r.pos+8 > len(r.data) {
+		r.err = fmt.Errorf("запись короче, чем указано в её заголовке")
+		return 0
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+	r.pos += 8
+	return v
+}
+
+// points читает count пар X Y
+func (r *shapeReader) points(count int) [][]float64 {
+	if count < 0 || r.pos+count*16 > len(r.data) {
+		r.err = fmt.Errorf("неверное число точек %d", count)
+		return nil
+	}
+	points := make([][]float64, count)
+	for i := range points {
+		points[i] = []float64{r.float64(), r.float64()}
+	}
+	return points
+}
+
+// parseShape разбирает одну запись .shp
+func parseShape(data []byte) (models.Geometry, error) {
+	r := &shapeReader{data: data}
+	shapeType := r.int32()
+
+	switch shapeType {
+	case shapeNull:
+		return nil, nil
+	case shapePoint, shapePointM, shapePointZ:
+		pos := []float64{r.float64(), r.float64()}
+		if shapeType == shapePointZ {
+			if z := r.float64(); z != 0 {
+				pos = append(pos, z)
+			}
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		return &models.PointGeometry{Coordinates: pos}, nil
+	case shapeMultiPoint, shapeMultiPointM, shapeMultiPointZ:
+		r.pos += 32 // охват
+		points := r.points(r.int32())
+		if shapeType == shapeMultiPointZ {
+			r.zValues(points)
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		return models.NewMultiPointGeometry(points...), nil
+	case shapePolyLine, shapePolyLineM, shapePolyLineZ, shapePolygon, shapePolygonM, shapePolygonZ:
+		r.pos += 32 // охват
+		numParts, numPoints := r.int32(), r.int32()
+		if r.err != nil || numParts < 0 || r.pos+numParts*4 > len(data) {
+			return nil, fmt.Errorf("неверное число частей %d", numParts)
+		}
+		parts := make([]int, numParts)
+		for i := range parts {
+			parts[i] = r.int32()
+		}
+		points := r.points(numPoints)
+		if shapeType == shapePolyLineZ || shapeType == shapePolygonZ {
+			r.zValues(points)
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		var lines [][][]float64
+		for i, start := range parts {
+			end := len(points)
+			if i+1 < len(parts) {
+				end = parts[i+1]
+			}
+			if start < 0 || start > end || end > len(points) {
+				return nil, fmt.Errorf("неверное начало части %d", i+1)
+			}
+			if end > start {
+				lines = append(lines, points[start:end])
+			}
+		}
+
+		switch shapeType {
+		case shapePolyLine, shapePolyLineM, shapePolyLineZ:
+			if len(lines) == 1 {
+				return models.NewLineStringGeometry(lines[0]...), nil
+			}
+			return models.NewMultiLineStringGeometry(lines...), nil
+		}
+		return polygonFromRings(lines), nil
+	}
+	return nil, fmt.Errorf("тип фигуры %d не поддерживается", shapeType)
+}
+
+// zValues дописывает третью координату из блока Z (диапазон и значения) к точкам.
+// Нулевая высота у всех точек фигуры не записывается
+func (r *shapeReader) zValues(points [][]float64) {
+	r.pos += 16 // диапазон Z
+	z := make([]float64, len(points))
+	flat := true
+	for i := range z {
+		z[i] = r.float64()
+		flat = flat && z[i] == 0
+	}
+	if flat {
+		return
+	}
+	for i := range points {
+		points[i] = append(points[i], z[i])
+	}
+}
+
+// polygonFromRings собирает полигоны из контуров: в шейп-файле внешние контуры идут по часовой стрелке,
+// а отверстия - против неё и относятся к внешнему контуру, в котором лежат.
+// Контуры переориентируются по правилу GeoJSON: внешние - против часовой стрелки
+func polygonFromRings(rings [][][]float64) models.Geometry {
+	var polygons [][][][]float64
+	var holes [][][]float64
+	for _, ring := range rings {
+		if signedArea(ring) <= 0 {
+			polygons = append(polygons, [][][]float64{reversed(ring)})
+		} else {
+			holes = append(holes, reversed(ring))
+		}
+	}
+
+	for _, hole := range holes {
+		owner := -1
+		for i, polygon := range polygons {
+			if containsPoint(polygon[0], hole[0]) {
+				owner = i
+				break
+			}
+		}
+		if owner == -1 {
+			// Отверстие вне всех контуров - значит, контур записан в обратном направлении
+			polygons = append(polygons, [][][]float64{reversed(hole)})
+			continue
+		}
+		polygons[owner] = append(polygons[owner], hole)
+	}
+
+	if len(polygons) == 1 {
+		return models.NewPolygonGeometry(polygons[0]...)
+	}
+	return models.NewMultiPolygonGeometry(polygons...)
+}
+
+// signedArea возвращает удвоенную площадь контура со знаком: больше нуля - против часовой стрелки
+func signedArea(ring [][]float64) float64 {
+	area := 0.0
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		area += ring[i][0]*ring[j][1] - ring[j][0]*ring[i][1]
+	}
+	return area
+}
+
+// containsPoint проверяет, лежит ли точка внутри контура (лучом)
+func containsPoint(ring [][]float64, point []float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi, xj, yj := ring[i][0], ring[i][1], ring[j][0], ring[j][1]
+		if (yi > point[1]) != (yj > point[1]) && point[0] < (xj-xi)*(point[1]-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// reversed возвращает контур в обратном порядке точек
+func reversed(ring [][]float64) [][]float64 {
+	result := make([][]float64, len(ring))
+	for i, pos := range ring {
+		result[len(ring)-1-i] = pos
+	}
+	return result
+}
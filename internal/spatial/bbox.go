@@ -0,0 +1,57 @@
+package spatial
+
+import (
+	"math"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// BBox прямоугольная область в градусах: [мин. долгота, мин. широта, макс. долгота, макс. широта]
+type BBox struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
+
+// Bounds вычисляет охват геометрий; false, если ни у одной геометрии нет координат
+func Bounds(geometries []models.Geometry) (BBox, bool) {
+	box := BBox{MinLon: math.Inf(1), MinLat: math.Inf(1), MaxLon: math.Inf(-1), MaxLat: math.Inf(-1)}
+	found := false
+	for _, geometry := range geometries {
+		if geometry == nil {
+			continue
+		}
+		models.MapPositions(geometry, func(pos []float64) []float64 {
+			if len(pos) >= 2 {
+				box.MinLon, box.MaxLon = math.Min(box.MinLon, pos[0]), math.Max(box.MaxLon, pos[0])
+				box.MinLat, box.MaxLat = math.Min(box.MinLat, pos[1]), math.Max(box.MaxLat, pos[1])
+				found = true
+			}
+			return pos
+		})
+	}
+	return box, found
+}
+
+// Contains сообщает, лежит ли точка [долгота, широта] в области (границы включаются)
+func (b BBox) Contains(pos []float64) bool {
+	return len(pos) >= 2 &&
+		pos[0] >= b.MinLon && pos[0] <= b.MaxLon &&
+		pos[1] >= b.MinLat && pos[1] <= b.MaxLat
+}
+
+// Grow расширяет область на долю ratio от её размеров, но не меньше чем на minDegrees градусов
+// с каждой стороны. Результат не выходит за пределы допустимых широт и долгот
+func (b BBox) Grow(ratio, minDegrees float64) BBox {
+	dLon := math.Max((b.MaxLon-b.MinLon)*ratio, minDegrees)
+	dLat := math.Max((b.MaxLat-b.MinLat)*ratio, minDegrees)
+	return BBox{
+		MinLon: math.Max(b.MinLon-dLon, -180),
+		MinLat: math.Max(b.MinLat-dLat, -90),
+		MaxLon: math.Min(b.MaxLon+dLon, 180),
+		MaxLat: math.Min(b.MaxLat+dLat, 90),
+	}
+}
+
+// ValidPosition сообщает, что широта и долгота точки в допустимых пределах (|широта| ≤ 90, |долгота| ≤ 180)
+func ValidPosition(pos []float64) bool {
+	return len(pos) >= 2 && math.Abs(pos[0]) <= 180 && math.Abs(pos[1]) <= 90
+}
@@ -0,0 +1,97 @@
+// Package spatial содержит геометрические проверки над models.Geometry.
+//
+// Координаты рассматриваются как плоские [долгота, широта]: для границ зон, залов и районов
+// этого достаточно, а объекты, пересекающие антимеридиан, не поддерживаются
+package spatial
+
+import "github.com/rmay1er/jgeo-excel/internal/models"
+
+// Contains сообщает, лежит ли точка [долгота, широта] внутри полигональной геометрии
+// (Polygon, MultiPolygon или коллекции, содержащей полигоны). Точка на границе считается внутренней.
+// Для остальных типов геометрии возвращается false
+func Contains(geometry models.Geometry, pos []float64) bool {
+	if geometry == nil || len(pos) < 2 {
+		return false
+	}
+
+	switch g := geometry.(type) {
+	case *models.PolygonGeometry:
+		return polygonContains(g.Coordinates, pos)
+	case *models.MultiPolygonGeometry:
+		for _, polygon := range g.Coordinates {
+			if polygonContains(polygon, pos) {
+				return true
+			}
+		}
+	case *models.CollectionGeometry:
+		for _, member := range g.Geometries {
+			if Contains(member, pos) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsPolygonal сообщает, может ли геометрия содержать точки
+func IsPolygonal(geometry models.Geometry) bool {
+	switch g := geometry.(type) {
+	case *models.PolygonGeometry, *models.MultiPolygonGeometry:
+		return !g.IsEmpty()
+	case *models.CollectionGeometry:
+		for _, member := range g.Geometries {
+			if IsPolygonal(member) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// polygonContains проверяет точку относительно внешнего контура и отверстий
+func polygonContains(rings [][][]float64, pos []float64) bool {
+	if len(rings) == 0 || !ringContains(rings[0], pos) {
+		return false
+	}
+	for _, hole := range rings[1:] {
+		if ringContains(hole, pos) && !onBoundary(hole, pos) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains проверяет точку методом трассировки луча; граница контура считается внутренней
+func ringContains(ring [][]float64, pos []float64) bool {
+	if onBoundary(ring, pos) {
+		return true
+	}
+
+	x, y := pos[0], pos[1]
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// onBoundary сообщает, лежит ли точка на одном из отрезков контура
+func onBoundary(ring [][]float64, pos []float64) bool {
+	x, y := pos[0], pos[1]
+	for i := 1; i < len(ring); i++ {
+		ax, ay := ring[i-1][0], ring[i-1][1]
+		bx, by := ring[i][0], ring[i][1]
+		cross := (bx-ax)*(y-ay) - (by-ay)*(x-ax)
+		if cross != 0 {
+			continue
+		}
+		if x >= min(ax, bx) && x <= max(ax, bx) && y >= min(ay, by) && y <= max(ay, by) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,305 @@
+// Package wkb читает и записывает геометрию в формате Well-Known Binary (OGC Simple Features, ISO 13249-3).
+//
+// Геометрия читается в models.Geometry, координаты - в порядке [x, y(, z)] = [долгота, широта(, высота)].
+// Высота записывается типами ISO (1001 - POINT Z и т.д.), при чтении понимаются также типы EWKB
+// (флаг 0x80000000); измерение M отбрасывается
+package wkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// Коды типов геометрии WKB без модификаторов Z и M
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// Marshal записывает геометрию в WKB с порядком байтов little-endian. Если хотя бы одна координата
+// содержит высоту, все координаты записываются с Z (недостающая высота - 0)
+func Marshal(geometry models.Geometry) ([]byte, error) {
+	if geometry == nil {
+		return nil, fmt.Errorf("геометрия не задана")
+	}
+	var buf bytes.Buffer
+	if err := write(&buf, geometry, geometry.HasZ()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write записывает одну геометрию с заголовком
+func write(buf *bytes.Buffer, geometry models.Geometry, hasZ bool) error {
+	code := 0
+	switch geometry.(type) {
+	case *models.PointGeometry:
+		code = wkbPoint
+	case *models.LineStringGeometry:
+		code = wkbLineString
+	case *models.PolygonGeometry:
+		code = wkbPolygon
+	case *models.MultiPointGeometry:
+		code = wkbMultiPoint
+	case *models.MultiLineStringGeometry:
+		code = wkbMultiLineString
+	case *models.MultiPolygonGeometry:
+		code = wkbMultiPolygon
+	case *models.CollectionGeometry:
+		code = wkbGeometryCollection
+	default:
+		return fmt.Errorf("неподдерживаемый тип геометрии '%s'", geometry.GeometryType())
+	}
+	if hasZ {
+		code += 1000
+	}
+	buf.WriteByte(1)
+	binary.Write(buf, binary.LittleEndian, uint32(code))
+
+	coord := func(pos []float64) {
+		binary.Write(buf, binary.LittleEndian, pos[:2])
+		if hasZ {
+			z := 0.0
+			if len(pos) > 2 {
+				z = pos[2]
+			}
+			binary.Write(buf, binary.LittleEndian, z)
+		}
+	}
+	line := func(positions [][]float64) {
+		binary.Write(buf, binary.LittleEndian, uint32(len(positions)))
+		for _, pos := range positions {
+			coord(pos)
+		}
+	}
+	rings := func(rings [][][]float64) {
+		binary.Write(buf, binary.LittleEndian, uint32(len(rings)))
+		for _, ring := range rings {
+			line(ring)
+		}
+	}
+
+	switch g := geometry.(type) {
+	case *models.PointGeometry:
+		if g.IsEmpty() {
+			// Пустая точка записывается координатами NaN
+			coord([]float64{math.NaN(), math.NaN(), math.NaN()})
+		} else {
+			coord(g.Coordinates)
+		}
+	case *models.LineStringGeometry:
+		line(g.Coordinates)
+	case *models.PolygonGeometry:
+		rings(g.Coordinates)
+	case *models.MultiPointGeometry:
+		binary.Write(buf, binary.LittleEndian, uint32(len(g.Coordinates)))
+		for _, pos := range g.Coordinates {
+			if err := write(buf, &models.PointGeometry{Coordinates: pos}, hasZ); err != nil {
+				return err
+			}
+		}
+	case *models.MultiLineStringGeometry:
+		binary.Write(buf, binary.LittleEndian, uint32(len(g.Coordinates)))
+		for _, positions := range g.Coordinates {
+			if err := write(buf, models.NewLineStringGeometry(positions...), hasZ); err != nil {
+				return err
+			}
+		}
+	case *models.MultiPolygonGeometry:
+		binary.Write(buf, binary.LittleEndian, uint32(len(g.Coordinates)))
+		for _, polygon := range g.Coordinates {
+			if err := write(buf, models.NewPolygonGeometry(polygon...), hasZ); err != nil {
+				return err
+			}
+		}
+	case *models.CollectionGeometry:
+		binary.Write(buf, binary.LittleEndian, uint32(len(g.Geometries)))
+		for _, member := range g.Geometries {
+			if err := write(buf, member, hasZ); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Parse разбирает WKB. Пустая точка (координаты NaN) возвращается как точка без координат
+func Parse(data []byte) (models.Geometry, error) {
+	r := &reader{data: data}
+	geometry := r.geometry()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return geometry, nil
+}
+
+// reader читает WKB по порядку; первая ошибка сохраняется в err
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if r.pos+n > len(r.data) {
+		r.err = fmt.Errorf("WKB обрезан на байте %d", r.pos)
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+// geometry читает геометрию с заголовком: порядок байтов и тип
+func (r *reader) geometry() models.Geometry {
+	order := r.take(1)
+	if r.err != nil {
+		return nil
+	}
+	var bo binary.ByteOrder = binary.LittleEndian
+	switch order[0] {
+	case 0:
+		bo = binary.BigEndian
+	case 1:
+	default:
+		r.err = fmt.Errorf("неверный порядок байтов WKB %d", order[0])
+		return nil
+	}
+
+	uint32At := func() uint32 {
+		b := r.take(4)
+		if b == nil {
+			return 0
+		}
+		return bo.Uint32(b)
+	}
+	code := uint32At()
+
+	// Размерность: ISO (1000 - Z, 2000 - M, 3000 - ZM) или флаги EWKB
+	hasZ := code&0x80000000 != 0
+	hasM := code&0x40000000 != 0
+	if code&0x20000000 != 0 {
+		uint32At() // SRID EWKB
+	}
+	code &= 0x0FFFFFFF
+	switch code / 1000 {
+	case 1:
+		hasZ = true
+	case 2:
+		hasM = true
+	case 3:
+		hasZ, hasM = true, true
+	}
+	code %= 1000
+
+	dims := 2
+	if hasZ {
+		dims++
+	}
+	if hasM {
+		dims++
+	}
+	coord := func() []float64 {
+		pos := make([]float64, dims)
+		for i := range pos {
+			b := r.take(8)
+			if b == nil {
+				return nil
+			}
+			pos[i] = math.Float64frombits(bo.Uint64(b))
+		}
+		if hasZ {
+			return pos[:3]
+		}
+		return pos[:2]
+	}
+	count := func() int {
+		n := int(uint32At())
+		if r.err == nil && n > (len(r.data)-r.pos)/4 {
+			r.err = fmt.Errorf("неверное число элементов WKB %d", n)
+		}
+		return n
+	}
+	line := func() [][]float64 {
+		n := count()
+		positions := make([][]float64, 0, max(n, 0))
+		for i := 0; i < n && r.err == nil; i++ {
+			positions = append(positions, coord())
+		}
+		return positions
+	}
+	rings := func() [][][]float64 {
+		n := count()
+		result := make([][][]float64, 0, max(n, 0))
+		for i := 0; i < n && r.err == nil; i++ {
+			result = append(result, line())
+		}
+		return result
+	}
+	members := func() []models.Geometry {
+		n := count()
+		result := make([]models.Geometry, 0, max(n, 0))
+		for i := 0; i < n && r.err == nil; i++ {
+			result = append(result, r.geometry())
+		}
+		return result
+	}
+
+	switch code {
+	case wkbPoint:
+		pos := coord()
+		if r.err != nil {
+			return nil
+		}
+		if math.IsNaN(pos[0]) && math.IsNaN(pos[1]) {
+			return &models.PointGeometry{}
+		}
+		return &models.PointGeometry{Coordinates: pos}
+	case wkbLineString:
+		return models.NewLineStringGeometry(line()...)
+	case wkbPolygon:
+		return models.NewPolygonGeometry(rings()...)
+	case wkbMultiPoint:
+		multi := &models.MultiPointGeometry{}
+		for _, member := range members() {
+			if point, ok := member.(*models.PointGeometry); ok && !point.IsEmpty() {
+				multi.Coordinates = append(multi.Coordinates, point.Coordinates)
+			}
+		}
+		return multi
+	case wkbMultiLineString:
+		multi := &models.MultiLineStringGeometry{}
+		for _, member := range members() {
+			if line, ok := member.(*models.LineStringGeometry); ok {
+				multi.Coordinates = append(multi.Coordinates, line.Coordinates)
+			}
+		}
+		return multi
+	case wkbMultiPolygon:
+		multi := &models.MultiPolygonGeometry{}
+		for _, member := range members() {
+			if polygon, ok := member.(*models.PolygonGeometry); ok {
+				multi.Coordinates = append(multi.Coordinates, polygon.Coordinates)
+			}
+		}
+		return multi
+	case wkbGeometryCollection:
+		return models.NewCollectionGeometry(members()...)
+	}
+	if r.err == nil {
+		r.err = fmt.Errorf("неподдерживаемый тип геометрии WKB %d", code)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package wkb
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		geometry models.Geometry
+	}{
+		{"точка", models.NewPointGeometry(37.6173, 55.7558)},
+		{"точка с высотой", models.NewPointGeometry(37.6173, 55.7558, 150)},
+		{"линия", models.NewLineStringGeometry([]float64{1, 2}, []float64{3, 4})},
+		{"полигон с отверстием", models.NewPolygonGeometry(
+			[][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			[][]float64{{0.2, 0.2}, {0.3, 0.2}, {0.3, 0.3}, {0.2, 0.2}},
+		)},
+		{"мультиточка", models.NewMultiPointGeometry([]float64{1, 2}, []float64{3, 4})},
+		{"мультилиния", models.NewMultiLineStringGeometry([][]float64{{1, 2, 3}, {3, 4, 5}}, [][]float64{{5, 6, 7}, {8, 9, 10}})},
+		{"мультиполигон", models.NewMultiPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})},
+		{"коллекция", models.NewCollectionGeometry(
+			models.NewPointGeometry(1, 2),
+			models.NewLineStringGeometry([]float64{1, 2}, []float64{3, 4}),
+		)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Marshal(tc.geometry)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			got, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.geometry) {
+				t.Errorf("Parse(Marshal(g)) = %#v; ожидается %#v", got, tc.geometry)
+			}
+		})
+	}
+}
+
+func TestMarshalMixedHeight(t *testing.T) {
+	// Недостающая высота записывается нулём
+	data, err := Marshal(models.NewLineStringGeometry([]float64{1, 2}, []float64{3, 4, 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.NewLineStringGeometry([]float64{1, 2, 0}, []float64{3, 4, 5})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(Marshal(g)) = %#v; ожидается %#v", got, want)
+	}
+}
+
+func TestParseKnown(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		want models.Geometry
+	}{
+		{"ISO little-endian", "0101000000000000000000f03f0000000000000040", models.NewPointGeometry(1, 2)},
+		{"ISO big-endian", "00000000013ff00000000000004000000000000000", models.NewPointGeometry(1, 2)},
+		{"ISO POINT Z", "01e9030000000000000000f03f00000000000000400000000000000840", models.NewPointGeometry(1, 2, 3)},
+		{"EWKB с SRID", "0101000020e6100000000000000000f03f0000000000000040", models.NewPointGeometry(1, 2)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Parse = %#v; ожидается %#v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package wkt
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// Marshal записывает геометрию в WKT. Если хотя бы одна координата содержит высоту,
+// используется форма с модификатором Z, а координатам без высоты записывается высота 0,
+// чтобы все координаты имели три значения. Пустая геометрия записывается как "<ТИП> EMPTY"
+func Marshal(geometry models.Geometry) (string, error) {
+	if geometry == nil {
+		return "", fmt.Errorf("геометрия не задана")
+	}
+
+	keyword := ""
+	for kw, t := range wktTypes {
+		if t == geometry.GeometryType() {
+			keyword = kw
+			break
+		}
+	}
+	if keyword == "" {
+		return "", fmt.Errorf("неподдерживаемый тип геометрии '%s'", geometry.GeometryType())
+	}
+
+	if geometry.IsEmpty() {
+		return keyword + " EMPTY", nil
+	}
+
+	z := geometry.HasZ()
+	var body string
+	switch g := geometry.(type) {
+	case *models.PointGeometry:
+		body = "(" + formatCoord(g.Coordinates, z) + ")"
+	case *models.LineStringGeometry:
+		body = formatLine(g.Coordinates, z)
+	case *models.MultiPointGeometry:
+		body = formatLine(g.Coordinates, z)
+	case *models.PolygonGeometry:
+		body = formatRings(g.Coordinates, z)
+	case *models.MultiLineStringGeometry:
+		body = formatRings(g.Coordinates, z)
+	case *models.MultiPolygonGeometry:
+		parts := make([]string, len(g.Coordinates))
+		for i, polygon := range g.Coordinates {
+			parts[i] = formatRings(polygon, z)
+		}
+		body = "(" + strings.Join(parts, ", ") + ")"
+	case *models.CollectionGeometry:
+		parts := make([]string, len(g.Geometries))
+		for i, member := range g.Geometries {
+			var err error
+			if parts[i], err = Marshal(member); err != nil {
+				return "", err
+			}
+		}
+		return keyword + " (" + strings.Join(parts, ", ") + ")", nil
+	default:
+		return "", fmt.Errorf("неподдерживаемый тип геометрии '%s'", geometry.GeometryType())
+	}
+
+	if z {
+		keyword += " Z"
+	}
+	return keyword + " " + body, nil
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatCoord записывает координату; z - форма с высотой: недостающая высота записывается как 0,
+// иначе записываются только долгота и широта
+func formatCoord(pos []float64, z bool) string {
+	values := pos[:min(len(pos), 2)]
+	if z {
+		values = append(slices.Clone(values), 0)
+		if len(pos) > 2 {
+			values[2] = pos[2]
+		}
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = formatNumber(v)
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatLine(line [][]float64, z bool) string {
+	parts := make([]string, len(line))
+	for i, pos := range line {
+		parts[i] = formatCoord(pos, z)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func formatRings(rings [][][]float64, z bool) string {
+	parts := make([]string, len(rings))
+	for i, ring := range rings {
+		parts[i] = formatLine(ring, z)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,297 @@
+// Package wkt читает и записывает геометрию в формате Well-Known Text (OGC Simple Features).
+//
+// Геометрия читается в models.Geometry, координаты - в порядке [x, y(, z)] = [долгота, широта(, высота)]
+package wkt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// Parse разбирает WKT (в том числе EWKT с префиксом SRID=...;).
+// Пустая геометрия (POINT EMPTY и т.п.) возвращается как геометрия своего типа без координат
+func Parse(text string) (models.Geometry, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(strings.ToUpper(text), "SRID=") {
+		idx := strings.Index(text, ";")
+		if idx < 0 {
+			return nil, fmt.Errorf("после SRID ожидается ';'")
+		}
+		text = text[idx+1:]
+	}
+
+	p := &parser{tokens: tokenize(text)}
+	geometry, err := p.geometry()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok != "" {
+		return nil, fmt.Errorf("лишний текст после геометрии: '%s'", tok)
+	}
+	return geometry, nil
+}
+
+// wktTypes сопоставляет ключевые слова WKT с типами GeoJSON
+var wktTypes = map[string]models.CordsDataType{
+	"POINT":              models.Point,
+	"LINESTRING":         models.LineString,
+	"POLYGON":            models.Polygon,
+	"MULTIPOINT":         models.MultiPoint,
+	"MULTILINESTRING":    models.MultiLineString,
+	"MULTIPOLYGON":       models.MultiPolygon,
+	"GEOMETRYCOLLECTION": models.GeometryCollection,
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+	// dims количество чисел в координате по модификатору (Z, M, ZM), 0 - не задано
+	dims int
+	// hasM координаты содержат измерение M, которое отбрасывается
+	hasM bool
+}
+
+// tokenize разбивает WKT на слова, числа и скобки
+func tokenize(text string) []string {
+	var tokens []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '(' || r == ')' || r == ',':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *parser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		if got == "" {
+			return fmt.Errorf("ожидается '%s', но текст закончился", tok)
+		}
+		return fmt.Errorf("ожидается '%s', получено '%s'", tok, got)
+	}
+	return nil
+}
+
+// geometry разбирает одну геометрию с ключевым словом типа
+func (p *parser) geometry() (models.Geometry, error) {
+	keyword := strings.ToUpper(p.next())
+	typ, ok := wktTypes[keyword]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный тип геометрии WKT '%s'", keyword)
+	}
+
+	// Модификатор размерности: POINT Z (...), POINT M (...), POINT ZM (...)
+	p.dims, p.hasM = 0, false
+	switch strings.ToUpper(p.peek()) {
+	case "Z":
+		p.next()
+		p.dims = 3
+	case "M":
+		p.next()
+		p.dims, p.hasM = 3, true
+	case "ZM":
+		p.next()
+		p.dims, p.hasM = 4, true
+	}
+
+	if strings.ToUpper(p.peek()) == "EMPTY" {
+		p.next()
+		return models.NewEmptyGeometry(typ)
+	}
+
+	var geometry models.Geometry
+	var err error
+	switch typ {
+	case models.Point:
+		var pos []float64
+		pos, err = p.point()
+		geometry = &models.PointGeometry{Coordinates: pos}
+	case models.LineString:
+		var line [][]float64
+		line, err = p.coordList(false)
+		geometry = &models.LineStringGeometry{Coordinates: line}
+	case models.MultiPoint:
+		var points [][]float64
+		points, err = p.coordList(true)
+		geometry = &models.MultiPointGeometry{Coordinates: points}
+	case models.Polygon:
+		var rings [][][]float64
+		rings, err = p.ringList()
+		geometry = &models.PolygonGeometry{Coordinates: rings}
+	case models.MultiLineString:
+		var lines [][][]float64
+		lines, err = p.ringList()
+		geometry = &models.MultiLineStringGeometry{Coordinates: lines}
+	case models.MultiPolygon:
+		var polygons [][][][]float64
+		polygons, err = p.polygonList()
+		geometry = &models.MultiPolygonGeometry{Coordinates: polygons}
+	case models.GeometryCollection:
+		var members []models.Geometry
+		members, err = p.collection()
+		geometry = &models.CollectionGeometry{Geometries: members}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", keyword, err)
+	}
+	return geometry, nil
+}
+
+// coord разбирает одну координату "x y [z] [m]"
+func (p *parser) coord() ([]float64, error) {
+	var values []float64
+	for {
+		tok := p.peek()
+		if tok == "" || tok == "," || tok == ")" {
+			break
+		}
+		val, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное число '%s'", tok)
+		}
+		values = append(values, val)
+		p.next()
+	}
+
+	if len(values) < 2 || len(values) > 4 || (p.dims > 0 && len(values) != p.dims) {
+		return nil, fmt.Errorf("неверное количество чисел в координате: %d", len(values))
+	}
+	// Измерение M не поддерживается GeoJSON и отбрасывается
+	if p.hasM || len(values) == 4 {
+		values = values[:len(values)-1]
+	}
+	return values, nil
+}
+
+// point разбирает "(x y)"
+func (p *parser) point() ([]float64, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	c, err := p.coord()
+	if err != nil {
+		return nil, err
+	}
+	return c, p.expect(")")
+}
+
+// coordList разбирает "(x y, x y, ...)"; для мультиточки допускается форма "((x y), (x y))"
+func (p *parser) coordList(allowParens bool) ([][]float64, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var result [][]float64
+	for {
+		var c []float64
+		var err error
+		if allowParens && p.peek() == "(" {
+			c, err = p.point()
+		} else {
+			c, err = p.coord()
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+
+		if p.peek() != "," {
+			break
+		}
+		p.next()
+	}
+	return result, p.expect(")")
+}
+
+// ringList разбирает "((...), (...))" - контуры полигона или линии мультилинии
+func (p *parser) ringList() ([][][]float64, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var result [][][]float64
+	for {
+		ring, err := p.coordList(false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ring)
+
+		if p.peek() != "," {
+			break
+		}
+		p.next()
+	}
+	return result, p.expect(")")
+}
+
+// polygonList разбирает "(((...)), ((...)))" - полигоны мультиполигона
+func (p *parser) polygonList() ([][][][]float64, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var result [][][][]float64
+	for {
+		polygon, err := p.ringList()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, polygon)
+
+		if p.peek() != "," {
+			break
+		}
+		p.next()
+	}
+	return result, p.expect(")")
+}
+
+// collection разбирает "(POINT (...), LINESTRING (...))"
+func (p *parser) collection() ([]models.Geometry, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var result []models.Geometry
+	for {
+		geometry, err := p.geometry()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, geometry)
+
+		if p.peek() != "," {
+			break
+		}
+		p.next()
+	}
+	return result, p.expect(")")
+}
@@ -0,0 +1,71 @@
+package wkt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+func TestParseMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"точка", "POINT(37.6173 55.7558)", "POINT (37.6173 55.7558)"},
+		{"точка с высотой", "point z (1 2 3)", "POINT Z (1 2 3)"},
+		{"линия", "LINESTRING(1 2,3 4)", "LINESTRING (1 2, 3 4)"},
+		{"полигон с отверстием", "POLYGON((0 0,1 0,1 1,0 0),(0.2 0.2,0.3 0.2,0.3 0.3,0.2 0.2))", "POLYGON ((0 0, 1 0, 1 1, 0 0), (0.2 0.2, 0.3 0.2, 0.3 0.3, 0.2 0.2))"},
+		{"мультиточка со скобками", "MULTIPOINT((1 2),(3 4))", "MULTIPOINT (1 2, 3 4)"},
+		{"мультиточка без скобок", "MULTIPOINT(1 2,3 4)", "MULTIPOINT (1 2, 3 4)"},
+		{"мультилиния", "MULTILINESTRING((1 2,3 4),(5 6,7 8))", "MULTILINESTRING ((1 2, 3 4), (5 6, 7 8))"},
+		{"мультиполигон", "MULTIPOLYGON(((0 0,1 0,1 1,0 0)))", "MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)))"},
+		{"коллекция", "GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(1 2,3 4))", "GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (1 2, 3 4))"},
+		{"пустая", "POINT EMPTY", "POINT EMPTY"},
+		{"EWKT", "SRID=4326;POINT(1 2)", "POINT (1 2)"},
+		{"высота у части точек", "LINESTRING(1 2 0, 3 4 5)", "LINESTRING Z (1 2 0, 3 4 5)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			geometry, err := Parse(tc.in)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tc.in, err)
+			}
+			got, err := Marshal(geometry)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Marshal(Parse(%q)) = %q; ожидается %q", tc.in, got, tc.want)
+			}
+			again, err := Parse(got)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", got, err)
+			}
+			if !reflect.DeepEqual(again, geometry) {
+				t.Errorf("Parse(%q) = %#v; ожидается %#v", got, again, geometry)
+			}
+		})
+	}
+}
+
+func TestMarshalMixedHeight(t *testing.T) {
+	// Недостающая высота записывается нулём, чтобы у всех координат было одно измерение
+	geometry := models.NewLineStringGeometry([]float64{1, 2}, []float64{3, 4, 5})
+	got, err := Marshal(geometry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "LINESTRING Z (1 2 0, 3 4 5)"; got != want {
+		t.Errorf("Marshal = %q; ожидается %q", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{"", "POINT", "POINT (1)", "LINESTRING (1 2, 3)", "CIRCLE (1 2)", "POINT (1 2"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q): ожидается ошибка", in)
+		}
+	}
+}
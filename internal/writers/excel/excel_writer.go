@@ -1,21 +1,63 @@
+// Package excel записывает объекты в книгу Excel.
+//
+// Лист "geojson" имеет единую разметку, которую понимает команда to-geojson:
+//
+//	Тип | ID | Имя | Описание | WKT | <оформление> | <свойства>
+//
+// Строка 1 - заголовки, данные начинаются со строки 2. Столбец ID добавляется, только если
+// у объектов есть идентификаторы. Геометрия хранится в WKT (порядок "долгота широта").
+// Если задана система координат (SetCRS), WKT записывается в ней: для прямоугольных систем
+// порядок "восток север" в метрах.
+// Столбцы оформления называются как свойства simplestyle (marker-color, stroke, fill-opacity и т.д.),
+// затем идут остальные свойства объектов в алфавитном порядке (свойство с именем основного столбца
+// получает заголовок с суффиксом " (свойство)"). Числа и логические значения
+// записываются как значения ячеек, вложенные объекты и массивы - как JSON.
+// Для обратного преобразования WriteRoundTripConfig создаёт конфигурацию to-geojson
 package excel
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"github.com/rmay1er/jgeo-excel/internal/crs"
 	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/wkt"
 	"github.com/xuri/excelize/v2"
 )
 
+// Заголовки основных столбцов листа
+const (
+	SheetName      = "geojson"
+	HeaderType     = "Тип"
+	HeaderID       = "ID"
+	HeaderName     = "Имя"
+	HeaderDesc     = "Описание"
+	HeaderGeometry = "WKT"
+)
+
 type ExcelWriter struct {
 	file *excelize.File
+	// crs система координат столбца WKT, nil - WGS 84
+	crs *crs.CRS
+	// Разметка последнего записанного листа, нужна для конфигурации обратного преобразования
+	hasID      bool
+	styleKeys  []string
+	properties []config.PropertyMapping
 }
 
 func NewExcelWriter() *ExcelWriter {
 	return &ExcelWriter{}
 }
 
+// SetCRS задаёт систему координат, в которую переводится геометрия при записи
+func (w *ExcelWriter) SetCRS(system *crs.CRS) {
+	w.crs = system
+}
+
 func (w *ExcelWriter) Write(data *[]models.CordsData, color ...string) error {
 	if data == nil || len(*data) == 0 {
 		log.Printf("No data provided for writing")
@@ -24,7 +66,7 @@ func (w *ExcelWriter) Write(data *[]models.CordsData, color ...string) error {
 
 	w.file = excelize.NewFile()
 	// Create new sheet with name "geojson"
-	sheetIndex, err := w.file.NewSheet("geojson")
+	sheetIndex, err := w.file.NewSheet(SheetName)
 	if err != nil {
 		log.Printf("Error creating new sheet 'geojson': %v", err)
 		return err
@@ -36,17 +78,55 @@ func (w *ExcelWriter) Write(data *[]models.CordsData, color ...string) error {
 	// Set "geojson" as active sheet
 	w.file.SetActiveSheet(sheetIndex)
 
-	header := []any{"Тип", "Имя", "Описание", "Координаты"}
+	w.collectLayout(*data)
+
+	header := []any{HeaderType}
+	if w.hasID {
+		header = append(header, HeaderID)
+	}
+	header = append(header, HeaderName, HeaderDesc, HeaderGeometry)
+	for _, key := range w.styleKeys {
+		header = append(header, key)
+	}
+	for _, prop := range w.properties {
+		header = append(header, prop.Column.Header)
+	}
 
-	if err := w.file.SetSheetRow("geojson", "A1", &header); err != nil {
+	if err := w.file.SetSheetRow(SheetName, "A1", &header); err != nil {
 		log.Printf("Error setting header row: %v", err)
 		return err
 	}
 
 	for i, item := range *data {
-		row := []any{item.Type, item.IconCaption, item.Description, item.Cords}
+		// Геометрия записывается в WKT, который читают большинство ГИС и сам jgeo-excel.
+		// У объекта без геометрии ячейка WKT остаётся пустой
+		var geometry string
+		if item.Geometry != nil {
+			projected := item.Geometry
+			if w.crs != nil && !w.crs.IsWGS84() {
+				projected = w.crs.GeometryFromWGS84(projected)
+			}
+			geometry, err = wkt.Marshal(projected)
+			if err != nil {
+				log.Printf("Error converting row %d to WKT: %v", i+2, err)
+				return err
+			}
+		}
+
+		row := []any{item.GeometryType()}
+		if w.hasID {
+			row = append(row, item.ID)
+		}
+		row = append(row, item.IconCaption, item.Description, geometry)
+		for _, key := range w.styleKeys {
+			row = append(row, cellValue(item.Style[key], false))
+		}
+		for _, prop := range w.properties {
+			row = append(row, cellValue(item.Properties[prop.Name], prop.Type == config.PropertyJSON))
+		}
+
 		cell := fmt.Sprintf("A%d", i+2)
-		if err := w.file.SetSheetRow("geojson", cell, &row); err != nil {
+		if err := w.file.SetSheetRow(SheetName, cell, &row); err != nil {
 			log.Printf("Error setting row %d: %v", i+2, err)
 			return err
 		}
@@ -55,6 +135,121 @@ func (w *ExcelWriter) Write(data *[]models.CordsData, color ...string) error {
 	return nil
 }
 
+// collectLayout определяет набор столбцов оформления и свойств и тип значений каждого свойства
+func (w *ExcelWriter) collectLayout(data []models.CordsData) {
+	w.hasID = false
+	w.styleKeys = nil
+	w.properties = nil
+
+	styles := make(map[string]bool)
+	types := make(map[string]string)
+	for _, item := range data {
+		if item.ID != "" {
+			w.hasID = true
+		}
+		for key := range item.Style {
+			styles[key] = true
+		}
+		for key, value := range item.Properties {
+			types[key] = mergeType(types[key], value)
+		}
+	}
+
+	for _, key := range models.StyleKeys {
+		if styles[key] {
+			w.styleKeys = append(w.styleKeys, key)
+		}
+	}
+
+	// Заголовки сравниваются так же, как при чтении: без учёта регистра и пробелов по краям
+	used := make(map[string]bool)
+	for _, header := range append([]string{HeaderType, HeaderID, HeaderName, HeaderDesc, HeaderGeometry}, w.styleKeys...) {
+		used[headerKey(header)] = true
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		typ := types[name]
+		if typ == "" {
+			typ = config.PropertyString
+		}
+		w.properties = append(w.properties, config.PropertyMapping{
+			Name:   name,
+			Column: config.ColumnRef{Header: propertyHeader(name, used)},
+			Type:   typ,
+		})
+	}
+}
+
+// propertyHeader возвращает заголовок столбца свойства. Свойство, имя которого совпадает с заголовком
+// основного столбца (Имя, ID, WKT и т.д.) или другого свойства, получает суффикс " (свойство)",
+// иначе при чтении его значение попало бы не в тот столбец
+func propertyHeader(name string, used map[string]bool) string {
+	header := name
+	if used[headerKey(header)] {
+		header = name + " (свойство)"
+	}
+	for i := 2; used[headerKey(header)]; i++ {
+		header = fmt.Sprintf("%s (свойство %d)", name, i)
+	}
+	used[headerKey(header)] = true
+	return header
+}
+
+// headerKey приводит заголовок к виду, в котором его сравнивает reader
+func headerKey(header string) string {
+	return strings.ToLower(strings.TrimSpace(header))
+}
+
+// mergeType уточняет тип столбца свойства с учётом очередного значения:
+// однотипные числа и логические значения сохраняют тип, объекты и массивы требуют JSON,
+// смешанные значения записываются как текст
+func mergeType(current string, value any) string {
+	var typ string
+	switch value.(type) {
+	case nil:
+		return current
+	case float64, int, int64:
+		typ = config.PropertyNumber
+	case bool:
+		typ = config.PropertyBool
+	case string:
+		typ = config.PropertyString
+	default:
+		typ = config.PropertyJSON
+	}
+
+	switch {
+	case current == "" || current == typ:
+		return typ
+	case current == config.PropertyJSON || typ == config.PropertyJSON:
+		return config.PropertyJSON
+	}
+	return config.PropertyString
+}
+
+// cellValue подготавливает значение свойства для записи в ячейку
+func cellValue(value any, asJSON bool) any {
+	if value == nil {
+		return nil
+	}
+	switch v := value.(type) {
+	case string, float64, int, int64, bool:
+		if !asJSON {
+			return v
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
 func (w *ExcelWriter) Save(path string) error {
 	if err := w.file.SaveAs(path); err != nil {
 		log.Printf("Error saving file to path '%s': %v", path, err)
@@ -0,0 +1,166 @@
+package excel
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// RejectedReportSheet название листа отчёта об отклонённых строках
+const RejectedReportSheet = "Отклонённые строки"
+
+// Цвет выделения отклонённых строк и автор комментариев в копии исходной книги
+const (
+	rejectedFill  = "FFC7CE"
+	commentAuthor = "jgeo-excel"
+)
+
+// WriteRejectedReport сохраняет отчёт об отклонённых строках: номер строки, столбец с ошибкой,
+// код причины, сообщение и исходные значения ячеек под заголовками headers.
+// Формат выбирается по расширению: .csv (UTF-8, разделитель ";") или .xlsx
+func WriteRejectedReport(path string, headers []string, rows []models.RejectedRow) error {
+	header := append([]string{"Строка", "Столбец", "Причина", "Сообщение"}, headers...)
+	records := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		record := []string{fmt.Sprint(r.Row), columnName(r.Column), string(r.Reason), r.Message}
+		records = append(records, append(record, r.Values...))
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return writeRejectedCSV(path, header, records)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", RejectedReportSheet); err != nil {
+		return fmt.Errorf("не удалось создать лист отчёта: %w", err)
+	}
+	if err := f.SetSheetRow(RejectedReportSheet, "A1", &header); err != nil {
+		return fmt.Errorf("не удалось записать заголовки отчёта: %w", err)
+	}
+	for i, record := range records {
+		if err := f.SetSheetRow(RejectedReportSheet, fmt.Sprintf("A%d", i+2), &record); err != nil {
+			return fmt.Errorf("не удалось записать строку отчёта %d: %w", i+2, err)
+		}
+	}
+
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("не удалось сохранить отчёт: %w", err)
+	}
+	return nil
+}
+
+// writeRejectedCSV сохраняет отчёт в CSV с меткой порядка байтов, чтобы Excel распознал UTF-8
+func writeRejectedCSV(path string, header []string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("не удалось создать отчёт: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString("\ufeff"); err != nil {
+		return fmt.Errorf("не удалось записать отчёт: %w", err)
+	}
+	w := csv.NewWriter(file)
+	w.Comma = ';'
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("не удалось записать отчёт: %w", err)
+	}
+	if err := w.WriteAll(records); err != nil {
+		return fmt.Errorf("не удалось записать отчёт: %w", err)
+	}
+	return file.Close()
+}
+
+// WriteHighlightedCopy сохраняет копию исходной книги source в target: отклонённые строки листа sheet
+// выделяются цветом с сохранением остального оформления, а к ячейке с ошибкой
+// (или к первой ячейке строки) добавляется комментарий с причиной
+func WriteHighlightedCopy(source, sheet, target string, rows []models.RejectedRow) error {
+	f, err := excelize.OpenFile(source)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть Excel файл: %w", err)
+	}
+	defer f.Close()
+
+	// Каждому стилю исходной книги соответствует его копия с заливкой
+	highlighted := make(map[int]int)
+	comments := make(map[string][]string)
+	var order []string
+
+	for _, r := range rows {
+		for col := 1; col <= max(len(r.Values), r.Column, 1); col++ {
+			cell, err := excelize.CoordinatesToCellName(col, r.Row)
+			if err != nil {
+				return err
+			}
+			if err := highlightCell(f, sheet, cell, highlighted); err != nil {
+				return err
+			}
+		}
+
+		cell, _ := excelize.CoordinatesToCellName(max(r.Column, 1), r.Row)
+		if _, ok := comments[cell]; !ok {
+			order = append(order, cell)
+		}
+		comments[cell] = append(comments[cell], fmt.Sprintf("%s: %s", r.Reason, r.Message))
+	}
+
+	for _, cell := range order {
+		// Комментарий ячейки заменяется, чтобы повторный запуск не накапливал одинаковые записи
+		if err := f.DeleteComment(sheet, cell); err != nil {
+			return fmt.Errorf("не удалось удалить комментарий ячейки %s: %w", cell, err)
+		}
+		comment := excelize.Comment{
+			Author: commentAuthor,
+			Cell:   cell,
+			Text:   strings.Join(comments[cell], "\n"),
+			Width:  300,
+			Height: 80,
+		}
+		if err := f.AddComment(sheet, comment); err != nil {
+			return fmt.Errorf("не удалось добавить комментарий к ячейке %s: %w", cell, err)
+		}
+	}
+
+	if err := f.SaveAs(target); err != nil {
+		return fmt.Errorf("не удалось сохранить копию книги: %w", err)
+	}
+	return nil
+}
+
+// highlightCell добавляет заливку к стилю ячейки
+func highlightCell(f *excelize.File, sheet, cell string, highlighted map[int]int) error {
+	styleID, err := f.GetCellStyle(sheet, cell)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать стиль ячейки %s: %w", cell, err)
+	}
+
+	newID, ok := highlighted[styleID]
+	if !ok {
+		style, err := f.GetStyle(styleID)
+		if err != nil {
+			return fmt.Errorf("не удалось прочитать стиль ячейки %s: %w", cell, err)
+		}
+		style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{rejectedFill}}
+		if newID, err = f.NewStyle(style); err != nil {
+			return fmt.Errorf("не удалось создать стиль выделения: %w", err)
+		}
+		highlighted[styleID] = newID
+	}
+	return f.SetCellStyle(sheet, cell, cell, newID)
+}
+
+// columnName возвращает букву столбца или пустую строку, если столбец не указан
+func columnName(col int) string {
+	if col <= 0 {
+		return ""
+	}
+	name, _ := excelize.ColumnNumberToName(col)
+	return name
+}
@@ -0,0 +1,116 @@
+package excel
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rmay1er/jgeo-excel/internal/config"
+	"go.yaml.in/yaml/v3"
+)
+
+// Структуры YAML повторяют формат config.example.yaml в том объёме,
+// который нужен для чтения листа, созданного ExcelWriter
+
+type roundTripColumn struct {
+	Header string `yaml:"header"`
+}
+
+type roundTripProperty struct {
+	Name   string `yaml:"name"`
+	Header string `yaml:"header"`
+	Type   string `yaml:"type,omitempty"`
+}
+
+type roundTripExcel struct {
+	File       string                     `yaml:"file"`
+	Sheet      string                     `yaml:"sheet"`
+	HeaderRow  int                        `yaml:"header_row"`
+	StartRow   int                        `yaml:"start_row"`
+	Columns    map[string]roundTripColumn `yaml:"columns"`
+	Properties []roundTripProperty        `yaml:"properties,omitempty"`
+	CRS        string                     `yaml:"crs,omitempty"`
+}
+
+type roundTripGeojson struct {
+	Input  string `yaml:"input"`
+	Output string `yaml:"output"`
+}
+
+type roundTripAppearance struct {
+	MarkerColor string                     `yaml:"marker_color"`
+	ColorColumn *roundTripColumn           `yaml:"color_column,omitempty"`
+	Columns     map[string]roundTripColumn `yaml:"columns,omitempty"`
+}
+
+type roundTripConfig struct {
+	Excel      roundTripExcel      `yaml:"excel"`
+	Geojson    roundTripGeojson    `yaml:"geojson"`
+	Appearance roundTripAppearance `yaml:"appearance"`
+}
+
+// WriteRoundTripConfig создаёт конфигурацию to-geojson для листа, записанного последним вызовом Write:
+// xlsxPath - путь к сохранённой книге, geojsonOutput - куда to-geojson запишет результат
+func (w *ExcelWriter) WriteRoundTripConfig(configPath, xlsxPath, geojsonOutput string) error {
+	if w.file == nil {
+		return fmt.Errorf("нет записанных данных для создания конфигурации")
+	}
+
+	cfg := roundTripConfig{
+		Excel: roundTripExcel{
+			File:      xlsxPath,
+			Sheet:     SheetName,
+			HeaderRow: 1,
+			StartRow:  2,
+			Columns: map[string]roundTripColumn{
+				"name":        {Header: HeaderName},
+				"description": {Header: HeaderDesc},
+				"wkt":         {Header: HeaderGeometry},
+			},
+		},
+		Geojson: roundTripGeojson{Output: geojsonOutput},
+		// Цвет по умолчанию отключён, чтобы не добавлять его объектам, у которых цвета не было
+		Appearance: roundTripAppearance{MarkerColor: config.NoColor},
+	}
+	if w.crs != nil && !w.crs.IsWGS84() {
+		cfg.Excel.CRS = w.crs.Code
+	}
+	if w.hasID {
+		cfg.Excel.Columns["id"] = roundTripColumn{Header: HeaderID}
+	}
+
+	for _, prop := range w.properties {
+		cfg.Excel.Properties = append(cfg.Excel.Properties, roundTripProperty{
+			Name:   prop.Name,
+			Header: prop.Column.Header,
+			Type:   prop.Type,
+		})
+	}
+
+	for _, key := range w.styleKeys {
+		option, ok := config.StyleOptionName(key)
+		if !ok {
+			continue
+		}
+		if option == "marker_color" {
+			cfg.Appearance.ColorColumn = &roundTripColumn{Header: key}
+			continue
+		}
+		if cfg.Appearance.Columns == nil {
+			cfg.Appearance.Columns = make(map[string]roundTripColumn)
+		}
+		cfg.Appearance.Columns[option] = roundTripColumn{Header: key}
+	}
+
+	data, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return fmt.Errorf("не удалось сформировать конфигурацию: %w", err)
+	}
+
+	header := "# Конфигурация для обратного преобразования Excel -> GeoJSON, создана командой to-excel.\n" +
+		"# Использование: jgeo-excel to-geojson --config " + configPath + "\n" +
+		"# Пустой geojson.input означает новую коллекцию; укажите базовый файл, чтобы дополнить его.\n"
+	if err := os.WriteFile(configPath, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("не удалось сохранить конфигурацию: %w", err)
+	}
+	return nil
+}
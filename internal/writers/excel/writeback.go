@@ -0,0 +1,127 @@
+package excel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteBackColumn столбец с результатами обработки, который дописывается в исходную книгу
+type WriteBackColumn struct {
+	Header string
+	// Column буква столбца; пустая строка - столбец с заголовком Header или первый свободный
+	Column string
+	// Values значения по номерам строк листа; прежние значения строк без результата очищаются
+	Values map[int]any
+}
+
+// WriteBack сохраняет в target книгу source с дописанными на лист sheet столбцами (target может совпадать с source).
+// Столбец без буквы записывается туда, где в строке headerRow уже есть его заголовок, иначе - в первый свободный,
+// поэтому повторный запуск обновляет те же столбцы. Значения заполняются начиная со строки startRow,
+// столбцы reserved (исходные данные) не перезаписываются. Оформление книги сохраняется
+func WriteBack(source, sheet, target string, headerRow, startRow int, reserved []int, columns []WriteBackColumn) error {
+	f, err := excelize.OpenFile(source)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть Excel файл: %w", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать строки из листа '%s': %w", sheet, err)
+	}
+	var headers []string
+	if headerRow >= 1 && headerRow <= len(rows) {
+		headers = rows[headerRow-1]
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	lastCol := width
+
+	assigned := make(map[int]string)
+	for _, column := range columns {
+		idx, err := writeBackColumnIndex(column, headers, &width)
+		if err != nil {
+			return err
+		}
+		name, _ := excelize.ColumnNumberToName(idx)
+		if slices.Contains(reserved, idx) {
+			return fmt.Errorf("столбец %s («%s») содержит исходные данные и не может быть перезаписан", name, column.Header)
+		}
+		if other, ok := assigned[idx]; ok {
+			return fmt.Errorf("столбцы «%s» и «%s» записываются в один столбец %s", other, column.Header, name)
+		}
+		assigned[idx] = column.Header
+
+		if headerRow >= 1 && headerRow < startRow {
+			if err := writeBackHeader(f, sheet, headerRow, idx, lastCol, column.Header); err != nil {
+				return err
+			}
+		}
+
+		for row := startRow; row <= len(rows); row++ {
+			cell, _ := excelize.CoordinatesToCellName(idx, row)
+			if value, ok := column.Values[row]; ok {
+				if err := f.SetCellValue(sheet, cell, value); err != nil {
+					return fmt.Errorf("не удалось записать ячейку %s: %w", cell, err)
+				}
+				continue
+			}
+			// Результат прошлого запуска очищается, если строка больше не обрабатывается
+			if existing, _ := f.GetCellValue(sheet, cell); existing != "" {
+				if err := f.SetCellValue(sheet, cell, nil); err != nil {
+					return fmt.Errorf("не удалось очистить ячейку %s: %w", cell, err)
+				}
+			}
+		}
+	}
+
+	if err := f.SaveAs(target); err != nil {
+		return fmt.Errorf("не удалось сохранить книгу с результатами: %w", err)
+	}
+	return nil
+}
+
+// writeBackColumnIndex возвращает номер столбца (A=1): по букве, по заголовку или первый свободный.
+// width - ширина заполненной части листа, увеличивается при добавлении столбца
+func writeBackColumnIndex(column WriteBackColumn, headers []string, width *int) (int, error) {
+	if column.Column != "" {
+		idx, err := excelize.ColumnNameToNumber(column.Column)
+		if err != nil {
+			return 0, fmt.Errorf("неверная буква столбца '%s': %w", column.Column, err)
+		}
+		*width = max(*width, idx)
+		return idx, nil
+	}
+
+	for i, header := range headers {
+		if strings.EqualFold(strings.TrimSpace(header), column.Header) {
+			return i + 1, nil
+		}
+	}
+	*width++
+	return *width, nil
+}
+
+// writeBackHeader записывает заголовок столбца. Новый столбец получает оформление
+// последнего заголовка листа lastCol, чтобы не выделяться среди остальных
+func writeBackHeader(f *excelize.File, sheet string, headerRow, idx, lastCol int, header string) error {
+	cell, _ := excelize.CoordinatesToCellName(idx, headerRow)
+	if err := f.SetCellValue(sheet, cell, header); err != nil {
+		return fmt.Errorf("не удалось записать заголовок %s: %w", cell, err)
+	}
+	if idx <= lastCol || lastCol == 0 {
+		return nil
+	}
+
+	last, _ := excelize.CoordinatesToCellName(lastCol, headerRow)
+	styleID, err := f.GetCellStyle(sheet, last)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать стиль ячейки %s: %w", last, err)
+	}
+	return f.SetCellStyle(sheet, cell, cell, styleID)
+}
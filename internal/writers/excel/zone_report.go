@@ -0,0 +1,48 @@
+package excel
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ZoneReportSheet название листа отчёта о привязке к зонам
+const ZoneReportSheet = "Зоны"
+
+// ZoneReportRow строка отчёта о привязке точки к зоне
+type ZoneReportRow struct {
+	ID     string
+	Name   string
+	Lon    float64
+	Lat    float64
+	Zone   string
+	ZoneID string
+	Status string
+}
+
+// WriteZoneReport сохраняет отчёт о привязке точек к зонам в книгу Excel
+func WriteZoneReport(path string, rows []ZoneReportRow) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", ZoneReportSheet); err != nil {
+		return fmt.Errorf("не удалось создать лист отчёта: %w", err)
+	}
+
+	header := []any{"ID", "Имя", "Долгота", "Широта", "Зона", "ID зоны", "Статус"}
+	if err := f.SetSheetRow(ZoneReportSheet, "A1", &header); err != nil {
+		return fmt.Errorf("не удалось записать заголовки отчёта: %w", err)
+	}
+
+	for i, r := range rows {
+		row := []any{r.ID, r.Name, r.Lon, r.Lat, r.Zone, r.ZoneID, r.Status}
+		if err := f.SetSheetRow(ZoneReportSheet, fmt.Sprintf("A%d", i+2), &row); err != nil {
+			return fmt.Errorf("не удалось записать строку отчёта %d: %w", i+2, err)
+		}
+	}
+
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("не удалось сохранить отчёт: %w", err)
+	}
+	return nil
+}
@@ -3,16 +3,48 @@ package writers
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	geojson "github.com/paulmach/go.geojson"
+	"github.com/rmay1er/jgeo-excel/internal/config"
 	"github.com/rmay1er/jgeo-excel/internal/models"
+	common "github.com/rmay1er/jgeo-excel/internal/writers"
 )
 
 // GeojsonWriter пишет координаты в GeoJSON формат
 type GeojsonWriter struct {
 	file *geojson.FeatureCollection
+	// defaultStyle оформление, которое применяется, если у объекта не задано своё
+	defaultStyle models.Style
+	// upsertKey ключ, по которому объекты обновляются на месте; пустая строка - объекты добавляются
+	upsertKey string
+	// deleteMissing удалять объекты, ключей которых нет среди записываемых данных
+	deleteMissing bool
+	// runID идентификатор запуска импорта, записывается в свойство ImportRunProperty
+	runID string
+	// results результаты записи объектов последнего вызова Write
+	results []WriteResult
+	// Rejections объекты с недопустимой геометрией, пропущенные последним вызовом Write
+	common.Rejections
 }
 
+// Действия с объектами при записи
+const (
+	ActionAdded   = "добавлен"
+	ActionUpdated = "обновлён"
+)
+
+// WriteResult результат записи одного объекта: идентификатор объекта коллекции и действие
+type WriteResult struct {
+	ID     string
+	Action string
+}
+
+// ImportRunProperty свойство, в котором сохраняется идентификатор запуска импорта.
+// По нему remove-marks --run удаляет точки, добавленные конкретным запуском
+const ImportRunProperty = "import_run"
+
 // NewGeojsonWriter создает новый GeoJSON writer и загружает файл
 func NewGeojsonWriter(path string) (*GeojsonWriter, error) {
 	f, err := os.ReadFile(path)
@@ -28,56 +60,276 @@ func NewGeojsonWriter(path string) (*GeojsonWriter, error) {
 	return &GeojsonWriter{file: featureCollection}, nil
 }
 
+// NewEmptyGeojsonWriter создает GeoJSON writer с пустой коллекцией
+func NewEmptyGeojsonWriter() *GeojsonWriter {
+	return &GeojsonWriter{file: geojson.NewFeatureCollection()}
+}
+
+// SetDefaultStyle задаёт оформление по умолчанию (marker-symbol, stroke, fill и т.д.).
+// Параметры применяются только к подходящим типам геометрии
+func (w *GeojsonWriter) SetDefaultStyle(style models.Style) {
+	w.defaultStyle = style
+}
+
+// SetUpsert включает обновление объектов по ключу: id, iconCaption или имя свойства.
+// Объекты с совпадающим ключом обновляются на месте, новые добавляются,
+// а при deleteMissing объекты с ключом, которого нет в данных, удаляются
+func (w *GeojsonWriter) SetUpsert(key string, deleteMissing bool) {
+	w.upsertKey = key
+	w.deleteMissing = deleteMissing
+}
+
+// SetRunID задаёт идентификатор запуска импорта, которым помечаются добавленные объекты;
+// пустая строка - объекты не помечаются
+func (w *GeojsonWriter) SetRunID(id string) {
+	w.runID = id
+}
+
 // Write добавляет координаты в GeoJSON коллекцию
 func (w *GeojsonWriter) Write(data *[]models.CordsData, color ...string) error {
 	if data == nil || len(*data) == 0 {
 		return fmt.Errorf("нет данных для записи")
 	}
 
-	for _, cord := range *data {
-		// Инвертируем координаты из Excel формата [широта, долгота] в GeoJSON формат [долгота, широта]
-		coords, ok := cord.Cords.([]float64)
-		if !ok {
-			return fmt.Errorf("неверный формат координат")
+	var index map[string][]*geojson.Feature
+	if w.upsertKey != "" {
+		index = w.indexFeatures()
+	}
+	seen := make(map[string]int)
+	var added, updated int
+	w.results = make([]WriteResult, 0, len(*data))
+	w.ResetRejected()
+
+	for i, cord := range *data {
+		key := ""
+		if w.upsertKey != "" {
+			key = w.dataKey(cord)
 		}
-		if len(coords) == 2 {
-			// Координаты из Excel приходят в формате [широта, долгота], меняем на [долгота, широта]
-			coords = []float64{coords[1], coords[0]}
+		if key != "" {
+			if first, ok := seen[key]; ok {
+				fmt.Printf("⚠️  Ключ '%s' объекта %d уже встречался в объекте %d, применяются последние данные\n", key, i+1, first)
+			} else {
+				seen[key] = i + 1
+			}
 		}
 
-		newPoint := geojson.NewFeature(geojson.NewPointGeometry(coords))
-
-		// Добавляем свойства
-		if cord.IconCaption != "" {
-			newPoint.SetProperty("iconCaption", cord.IconCaption)
+		// Пустая геометрия и объект без геометрии записываются с geometry: null.
+		// Объект с недопустимой геометрией пропускается, остальные записываются. Его ключ уже
+		// отмечен как встреченный, поэтому delete_missing не удалит существующий объект с этим ключом
+		// В результатах записи для него остаётся пустой WriteResult, чтобы порядок совпадал с данными
+		if !w.CheckGeometry(i+1, cord) {
+			w.results = append(w.results, WriteResult{})
+			continue
 		}
-		if cord.Description != "" {
-			newPoint.SetProperty("description", cord.Description)
+
+		if key != "" {
+			if features, ok := index[key]; ok {
+				for _, feature := range features {
+					w.fillFeature(feature, cord, false, color...)
+				}
+				updated++
+				w.results = append(w.results, WriteResult{ID: keyString(features[0].ID), Action: ActionUpdated})
+				continue
+			}
 		}
-		if color != nil && color[0] != "" {
-			newPoint.SetProperty("marker-color", color[0])
+
+		feature := geojson.NewFeature(nil)
+		w.fillFeature(feature, cord, true, color...)
+		w.file.AddFeature(feature)
+		added++
+		w.results = append(w.results, WriteResult{ID: keyString(feature.ID), Action: ActionAdded})
+		if key != "" {
+			index[key] = append(index[key], feature)
 		}
+	}
 
-		w.file.AddFeature(newPoint)
+	if w.upsertKey != "" {
+		removed := 0
+		if w.deleteMissing {
+			removed = w.removeMissing(seen)
+		}
+		fmt.Printf("🔁 Обновление по ключу '%s': добавлено %d, обновлено %d, удалено %d\n", w.upsertKey, added, updated, removed)
 	}
 
 	return nil
 }
 
-// RemoveAllPoints удаляет все точки (Point features) из коллекции
-func (w *GeojsonWriter) RemoveAllPoints() error {
+// Results возвращает результаты записи объектов последнего вызова Write в порядке записываемых данных
+func (w *GeojsonWriter) Results() []WriteResult {
+	return w.results
+}
+
+// fillFeature записывает в объект геометрию, идентификатор, свойства и оформление.
+// Свойства, которых нет в данных, у существующего объекта сохраняются. Общий цвет маркеров
+// и оформление по умолчанию получают только новые объекты (isNew), чтобы при обновлении
+// не затереть оформление, изменённое вручную. Идентификатор запуска тоже записывается только
+// в новые объекты: обновлённые остаются за запуском, который их добавил
+func (w *GeojsonWriter) fillFeature(feature *geojson.Feature, cord models.CordsData, isNew bool, color ...string) {
+	feature.Geometry = models.GeometryToGeoJSON(cord.Geometry)
+	if cord.ID != "" {
+		feature.ID = featureID(cord.ID)
+	}
+
+	// Добавляем свойства: сначала дополнительные, затем основные,
+	// чтобы имя, описание и цвет не перезаписывались столбцами с тем же ключом
+	for key, value := range cord.Properties {
+		feature.SetProperty(key, value)
+	}
+	if cord.IconCaption != "" {
+		feature.SetProperty("iconCaption", cord.IconCaption)
+	}
+	if cord.Description != "" {
+		feature.SetProperty("description", cord.Description)
+	}
+	// Оформление строки имеет приоритет над общим цветом маркеров и оформлением по умолчанию
+	if isNew {
+		if color != nil && color[0] != "" && models.StyleAppliesTo(models.StyleMarkerColor, geometryType(feature)) {
+			feature.SetProperty(models.StyleMarkerColor, color[0])
+		}
+		applyStyle(feature, w.defaultStyle)
+	}
+	applyStyle(feature, cord.Style)
+	if isNew && w.runID != "" {
+		feature.SetProperty(ImportRunProperty, w.runID)
+	}
+}
+
+// indexFeatures группирует объекты коллекции по значению ключа обновления
+func (w *GeojsonWriter) indexFeatures() map[string][]*geojson.Feature {
+	index := make(map[string][]*geojson.Feature)
+	for _, feature := range w.file.Features {
+		if key := w.featureKey(feature); key != "" {
+			index[key] = append(index[key], feature)
+		}
+	}
+	return index
+}
+
+// removeMissing удаляет объекты, ключ которых не встретился в данных. Объекты без ключа сохраняются
+func (w *GeojsonWriter) removeMissing(seen map[string]int) int {
+	kept := w.file.Features[:0]
+	removed := 0
+	for _, feature := range w.file.Features {
+		key := w.featureKey(feature)
+		if _, ok := seen[key]; key != "" && !ok {
+			removed++
+			continue
+		}
+		kept = append(kept, feature)
+	}
+	w.file.Features = kept
+	return removed
+}
+
+// featureKey возвращает значение ключа обновления у объекта коллекции
+func (w *GeojsonWriter) featureKey(feature *geojson.Feature) string {
+	return FeatureKey(feature, w.upsertKey)
+}
+
+// FeatureKey возвращает значение ключа объекта: id - идентификатор, иначе свойство с именем key.
+// Числа приводятся к строке без дробной части, чтобы 5 из GeoJSON совпадало с "5" из таблицы
+func FeatureKey(feature *geojson.Feature, key string) string {
+	if key == config.UpsertKeyID {
+		return keyString(feature.ID)
+	}
+	return keyString(feature.Properties[key])
+}
+
+// dataKey возвращает значение ключа обновления у записываемого объекта
+func (w *GeojsonWriter) dataKey(cord models.CordsData) string {
+	switch w.upsertKey {
+	case config.UpsertKeyID:
+		return cord.ID
+	case config.UpsertKeyName:
+		return cord.IconCaption
+	}
+	return keyString(cord.Properties[w.upsertKey])
+}
+
+// keyString приводит значение ключа к строке так, чтобы число 5 из GeoJSON совпало с "5" из таблицы
+func keyString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// applyStyle записывает параметры оформления, подходящие для геометрии объекта
+func applyStyle(feature *geojson.Feature, style models.Style) {
+	for key, value := range style {
+		if models.StyleAppliesTo(key, geometryType(feature)) {
+			feature.SetProperty(key, value)
+		}
+	}
+}
+
+// featureID записывает числовые идентификаторы числом, остальные - строкой
+func featureID(id string) any {
+	if num, err := strconv.ParseInt(id, 10, 64); err == nil && strconv.FormatInt(num, 10) == id {
+		return num
+	}
+	return id
+}
+
+// geometryType возвращает тип геометрии объекта или пустую строку для объекта без геометрии
+func geometryType(feature *geojson.Feature) string {
+	if feature.Geometry == nil {
+		return ""
+	}
+	return string(feature.Geometry.Type)
+}
+
+// FeatureMatcher отбирает объекты коллекции
+type FeatureMatcher func(feature *geojson.Feature) bool
+
+// Features возвращает объекты коллекции, для которых match возвращает true; nil - все объекты
+func (w *GeojsonWriter) Features(match FeatureMatcher) []*geojson.Feature {
 	if w.file == nil {
 		return nil
 	}
 
-	var newFeatures []*geojson.Feature
+	var result []*geojson.Feature
+	for _, feature := range w.file.Features {
+		if match == nil || match(feature) {
+			result = append(result, feature)
+		}
+	}
+	return result
+}
+
+// RemovePoints удаляет точки (Point features), для которых match возвращает true (nil - все точки),
+// и возвращает удалённые объекты
+func (w *GeojsonWriter) RemovePoints(match FeatureMatcher) []*geojson.Feature {
+	if w.file == nil {
+		return nil
+	}
+
+	var newFeatures, removed []*geojson.Feature
 	for _, feature := range w.file.Features {
 		// Проверяем, является ли геометрия точкой
-		if feature.Geometry == nil || feature.Geometry.Type != geojson.GeometryPoint {
-			newFeatures = append(newFeatures, feature)
+		isPoint := feature.Geometry != nil && feature.Geometry.Type == geojson.GeometryPoint
+		if isPoint && (match == nil || match(feature)) {
+			removed = append(removed, feature)
+			continue
 		}
+		newFeatures = append(newFeatures, feature)
 	}
 	w.file.Features = newFeatures
+	return removed
+}
+
+// RemoveAllPoints удаляет все точки (Point features) из коллекции
+func (w *GeojsonWriter) RemoveAllPoints() error {
+	w.RemovePoints(nil)
 	return nil
 }
 
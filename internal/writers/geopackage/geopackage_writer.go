@@ -0,0 +1,222 @@
+// Package geopackage записывает объекты в файл GeoPackage (.gpkg) - базу SQLite по стандарту OGC.
+//
+// По умолчанию объекты раскладываются по слоям по типу геометрии: points, multipoints, lines,
+// multilines, polygons, multipolygons и collections. Если задано свойство слоя (SetLayerProperty),
+// слои называются по его значениям, а тип геометрии слоя - общий тип его объектов или GEOMETRY.
+// Идентификатор, название, описание, оформление и свойства объектов становятся столбцами таблицы слоя,
+// координаты записываются в WGS 84 (EPSG:4326). Файл создаётся драйвером SQLite на чистом Go, без cgo
+package geopackage
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/writers"
+	_ "modernc.org/sqlite"
+)
+
+// Слои по типу геометрии в порядке записи
+var typeLayers = []struct {
+	typ  models.CordsDataType
+	name string
+}{
+	{models.Point, "points"},
+	{models.MultiPoint, "multipoints"},
+	{models.LineString, "lines"},
+	{models.MultiLineString, "multilines"},
+	{models.Polygon, "polygons"},
+	{models.MultiPolygon, "multipolygons"},
+	{models.GeometryCollection, "collections"},
+}
+
+// noGroupLayer слой для объектов без значения свойства слоя
+const noGroupLayer = "без группы"
+
+// GeoPackageWriter собирает объекты и записывает их слоями в GeoPackage
+type GeoPackageWriter struct {
+	items         []models.CordsData
+	layerProperty string
+	// Rejections объекты с недопустимой геометрией, пропущенные последним вызовом Write
+	writers.Rejections
+}
+
+// NewGeoPackageWriter создаёт writer без объектов
+func NewGeoPackageWriter() *GeoPackageWriter {
+	return &GeoPackageWriter{}
+}
+
+// SetLayerProperty задаёт свойство, по значению которого объекты раскладываются по слоям;
+// пустая строка - слой для каждого типа геометрии
+func (w *GeoPackageWriter) SetLayerProperty(name string) {
+	w.layerProperty = name
+}
+
+// Write добавляет объекты. Оформление записывается в столбцы объектов, параметр color нужен для интерфейса Writer
+func (w *GeoPackageWriter) Write(data *[]models.CordsData, color ...string) error {
+	if data == nil || len(*data) == 0 {
+		return fmt.Errorf("нет данных для записи")
+	}
+	w.ResetRejected()
+
+	for i, item := range *data {
+		if item.Geometry == nil || item.Geometry.IsEmpty() {
+			fmt.Printf("⚠️  Объект %d ('%s') без геометрии пропущен\n", i+1, item.IconCaption)
+			continue
+		}
+		if !w.CheckGeometry(i+1, item) {
+			continue
+		}
+		w.items = append(w.items, item)
+	}
+	return nil
+}
+
+// Save создаёт файл GeoPackage; существующий файл перезаписывается. База собирается во временном
+// файле рядом с результатом и заменяет его только после успешной записи, чтобы ошибка не оставила
+// вместо прежнего файла пустой или недописанный
+func (w *GeoPackageWriter) Save(path string) error {
+	layers := w.layers()
+	if len(layers) == 0 {
+		return fmt.Errorf("нет объектов с геометрией для GeoPackage")
+	}
+
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("не удалось удалить временный файл %s: %w", tmp, err)
+	}
+	names, err := build(tmp, layers)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("не удалось перезаписать файл %s: %w", path, err)
+	}
+
+	if len(names) > 1 {
+		fmt.Printf("🗂️  Слои GeoPackage: %s\n", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// build создаёт базу GeoPackage в файле path и записывает слои; возвращает имена таблиц слоёв
+func build(path string, layers []*layer) ([]string, error) {
+	uri, err := fileURI(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать GeoPackage: %w", err)
+	}
+	db, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать GeoPackage: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать GeoPackage: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createSchema(tx); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(layers))
+	for _, l := range layers {
+		if err := l.write(tx); err != nil {
+			return nil, fmt.Errorf("слой %s: %w", l.name, err)
+		}
+		names = append(names, l.name)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось записать GeoPackage: %w", err)
+	}
+	if err := db.Close(); err != nil {
+		return nil, fmt.Errorf("не удалось записать GeoPackage: %w", err)
+	}
+	return names, nil
+}
+
+// fileURI возвращает URI SQLite для создания файла. Путь экранируется, чтобы символы ?, # и %
+// в имени файла не считались параметрами подключения
+func fileURI(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	abs = filepath.ToSlash(abs)
+	if !strings.HasPrefix(abs, "/") {
+		// Путь Windows с буквой диска: file:///C:/...
+		abs = "/" + abs
+	}
+	uri := url.URL{Scheme: "file", Path: abs, RawQuery: "mode=rwc"}
+	return uri.String(), nil
+}
+
+// Close освобождает ресурсы writer
+func (w *GeoPackageWriter) Close() error {
+	w.items = nil
+	return nil
+}
+
+// layers раскладывает объекты по слоям: по типу геометрии или по значению свойства слоя
+// (в порядке первого появления). Имена таблиц уникальны без учёта регистра
+func (w *GeoPackageWriter) layers() []*layer {
+	var layers []*layer
+	byName := make(map[string]*layer)
+	add := func(name string, item models.CordsData) {
+		l, ok := byName[name]
+		if !ok {
+			l = &layer{name: name}
+			byName[name] = l
+			layers = append(layers, l)
+		}
+		l.items = append(l.items, item)
+	}
+
+	if w.layerProperty == "" {
+		for _, typeLayer := range typeLayers {
+			for _, item := range w.items {
+				if item.Geometry.GeometryType() == typeLayer.typ {
+					add(typeLayer.name, item)
+				}
+			}
+		}
+	} else {
+		for _, item := range w.items {
+			name := noGroupLayer
+			if value, ok := item.Properties[w.layerProperty]; ok && value != nil {
+				if text := strings.TrimSpace(textValue(value)); text != "" {
+					name = text
+				}
+			}
+			add(name, item)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, l := range layers {
+		l.name = tableName(l.name, used)
+	}
+	return layers
+}
+
+// tableName возвращает имя таблицы слоя: имена, зарезервированные GeoPackage и SQLite,
+// получают префикс layer_, повторы - суффикс _1, _2 и т.д.
+func tableName(name string, used map[string]bool) string {
+	lower := strings.ToLower(name)
+	if strings.HasPrefix(lower, "gpkg_") || strings.HasPrefix(lower, "sqlite_") || strings.HasPrefix(lower, "rtree_") {
+		name = "layer_" + name
+	}
+	result := name
+	for i := 1; used[strings.ToLower(result)]; i++ {
+		result = fmt.Sprintf("%s_%d", name, i)
+	}
+	used[strings.ToLower(result)] = true
+	return result
+}
@@ -0,0 +1,295 @@
+package geopackage
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/spatial"
+	"github.com/rmay1er/jgeo-excel/internal/wkb"
+)
+
+// Столбцы таблицы слоя, которые создаются всегда
+const (
+	fidColumn  = "fid"
+	geomColumn = "geom"
+)
+
+// layer объекты одной таблицы GeoPackage
+type layer struct {
+	name  string
+	items []models.CordsData
+}
+
+// column столбец атрибутов и функция, которая берёт его значение из объекта
+type column struct {
+	name string
+	kind string
+	// json значения столбца - объекты и массивы, записанные как JSON (mime_type в gpkg_data_columns)
+	json  bool
+	value func(item models.CordsData) any
+}
+
+// write создаёт таблицу слоя, регистрирует её в gpkg_contents и gpkg_geometry_columns и записывает объекты
+func (l *layer) write(tx *sql.Tx) error {
+	columns := l.columns()
+
+	definitions := []string{
+		quote(fidColumn) + " INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL",
+		quote(geomColumn) + " " + l.geometryType(),
+	}
+	for _, col := range columns {
+		definitions = append(definitions, quote(col.name)+" "+col.kind)
+	}
+	create := fmt.Sprintf("CREATE TABLE %s (%s)", quote(l.name), strings.Join(definitions, ", "))
+	if _, err := tx.Exec(create); err != nil {
+		return fmt.Errorf("не удалось создать таблицу: %w", err)
+	}
+
+	geometries := make([]models.Geometry, len(l.items))
+	hasZ := 0
+	for i, item := range l.items {
+		geometries[i] = item.Geometry
+		if item.Geometry.HasZ() {
+			hasZ = 1
+		}
+	}
+	box, _ := spatial.Bounds(geometries)
+	if _, err := tx.Exec(`INSERT INTO gpkg_contents
+		(table_name, data_type, identifier, min_x, min_y, max_x, max_y, srs_id)
+		VALUES (?, 'features', ?, ?, ?, ?, ?, ?)`,
+		l.name, l.name, box.MinLon, box.MinLat, box.MaxLon, box.MaxLat, wgs84SRSID); err != nil {
+		return fmt.Errorf("не удалось зарегистрировать слой: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO gpkg_geometry_columns
+		(table_name, column_name, geometry_type_name, srs_id, z, m)
+		VALUES (?, ?, ?, ?, ?, 0)`,
+		l.name, geomColumn, l.geometryType(), wgs84SRSID, hasZ); err != nil {
+		return fmt.Errorf("не удалось зарегистрировать слой: %w", err)
+	}
+	for _, col := range columns {
+		if !col.json {
+			continue
+		}
+		if err := createDataColumns(tx); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO gpkg_data_columns (table_name, column_name, mime_type)
+			VALUES (?, ?, ?)`, l.name, col.name, jsonMimeType); err != nil {
+			return fmt.Errorf("не удалось описать столбец %s: %w", col.name, err)
+		}
+	}
+
+	names := []string{quote(geomColumn)}
+	for _, col := range columns {
+		names = append(names, quote(col.name))
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?%s)",
+		quote(l.name), strings.Join(names, ", "), strings.Repeat(", ?", len(columns)))
+	stmt, err := tx.Prepare(insert)
+	if err != nil {
+		return fmt.Errorf("не удалось подготовить запись объектов: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, item := range l.items {
+		blob, err := encodeGeometry(item.Geometry)
+		if err != nil {
+			return fmt.Errorf("объект %d ('%s'): %w", i+1, item.IconCaption, err)
+		}
+		args := []any{blob}
+		for _, col := range columns {
+			args = append(args, columnValue(col, col.value(item)))
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return fmt.Errorf("объект %d ('%s'): %w", i+1, item.IconCaption, err)
+		}
+	}
+	return nil
+}
+
+// geometryType возвращает тип геометрии слоя: общий тип объектов или GEOMETRY для смешанного слоя
+func (l *layer) geometryType() string {
+	typ := l.items[0].Geometry.GeometryType()
+	for _, item := range l.items[1:] {
+		if item.Geometry.GeometryType() != typ {
+			return "GEOMETRY"
+		}
+	}
+	if typ == models.GeometryCollection {
+		return "GEOMETRYCOLLECTION"
+	}
+	return strings.ToUpper(string(typ))
+}
+
+// builtinColumns столбцы, которые reader GeoPackage читает в идентификатор, название и описание
+var builtinColumns = []string{"id", "name", "description", "descr"}
+
+// columns подбирает столбцы атрибутов: id, name и description, если они заполнены хотя бы у одного
+// объекта, затем оформление в порядке models.StyleKeys и свойства в алфавитном порядке.
+// Свойства с именами встроенных столбцов и оформления получают суффикс, даже если такой столбец
+// не записан, чтобы при чтении не стать названием или оформлением. Тип столбца выбирается по значениям
+func (l *layer) columns() []column {
+	var columns []column
+	used := map[string]bool{fidColumn: true, geomColumn: true}
+	add := func(key string, value func(item models.CordsData) any) {
+		values := make([]any, 0, len(l.items))
+		for _, item := range l.items {
+			if v := value(item); v != nil && v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return
+		}
+		columns = append(columns, column{
+			name:  columnName(key, used),
+			kind:  columnType(values),
+			json:  jsonValues(values),
+			value: value,
+		})
+	}
+
+	add("id", func(item models.CordsData) any { return item.ID })
+	add("name", func(item models.CordsData) any { return item.IconCaption })
+	add("description", func(item models.CordsData) any { return item.Description })
+	for _, key := range models.StyleKeys {
+		add(key, func(item models.CordsData) any { return item.Style[key] })
+	}
+	for _, name := range append(slices.Clone(builtinColumns), models.StyleKeys...) {
+		used[strings.ToLower(name)] = true
+	}
+
+	keys := make(map[string]bool)
+	for _, item := range l.items {
+		for key := range item.Properties {
+			keys[key] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		add(key, func(item models.CordsData) any { return item.Properties[key] })
+	}
+	return columns
+}
+
+// columnName возвращает имя столбца, не совпадающее без учёта регистра с уже занятыми
+func columnName(key string, used map[string]bool) string {
+	name := key
+	for i := 1; used[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s_%d", key, i)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// columnType выбирает тип столбца: логические значения - BOOLEAN, целые числа - INTEGER,
+// остальные числа - REAL, всё прочее - TEXT
+func columnType(values []any) string {
+	numbers, integers, bools := true, true, true
+	for _, value := range values {
+		_, isBool := value.(bool)
+		bools = bools && isBool
+		num, isNumber := value.(float64)
+		numbers = numbers && isNumber
+		integers = integers && isNumber && num == math.Trunc(num) && math.Abs(num) < 1<<53
+	}
+	switch {
+	case bools:
+		return "BOOLEAN"
+	case integers:
+		return "INTEGER"
+	case numbers:
+		return "REAL"
+	}
+	return "TEXT"
+}
+
+// jsonValues сообщает, что все значения столбца - вложенные объекты или массивы
+func jsonValues(values []any) bool {
+	for _, value := range values {
+		switch value.(type) {
+		case map[string]any, []any:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// columnValue приводит значение к типу столбца; пустое значение - NULL
+func columnValue(col column, value any) any {
+	if value == nil || value == "" {
+		return nil
+	}
+	switch col.kind {
+	case "BOOLEAN":
+		if v, _ := value.(bool); v {
+			return 1
+		}
+		return 0
+	case "INTEGER":
+		num, _ := value.(float64)
+		return int64(num)
+	case "REAL":
+		return value
+	}
+	return textValue(value)
+}
+
+// textValue приводит значение свойства к тексту: вложенные объекты и массивы - как JSON
+func textValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any, []any:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// encodeGeometry записывает геометрию в формате GeoPackageBinary: заголовок GP с кодом системы
+// координат и охватом (у точек охват не записывается), затем WKB
+func encodeGeometry(geometry models.Geometry) ([]byte, error) {
+	data, err := wkb.Marshal(geometry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Флаги: бит 0 - порядок байтов little-endian, биты 1-3 - вид охвата (1 - minx, maxx, miny, maxy)
+	flags := byte(1)
+	var envelope []float64
+	if _, ok := geometry.(*models.PointGeometry); !ok {
+		box, _ := spatial.Bounds([]models.Geometry{geometry})
+		flags |= 1 << 1
+		envelope = []float64{box.MinLon, box.MaxLon, box.MinLat, box.MaxLat}
+	}
+
+	blob := make([]byte, 0, 8+8*len(envelope)+len(data))
+	blob = append(blob, 'G', 'P', 0, flags)
+	blob = binary.LittleEndian.AppendUint32(blob, uint32(wgs84SRSID))
+	for _, value := range envelope {
+		blob = binary.LittleEndian.AppendUint64(blob, math.Float64bits(value))
+	}
+	return append(blob, data...), nil
+}
+
+// quote заключает имя таблицы или столбца SQLite в двойные кавычки
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,129 @@
+package geopackage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Идентификаторы GeoPackage в заголовке базы SQLite: application_id "GPKG" и версия 1.4.0
+const (
+	applicationID = 0x47504B47
+	userVersion   = 10400
+)
+
+// wgs84SRSID код системы координат слоёв в gpkg_spatial_ref_sys
+const wgs84SRSID = 4326
+
+// schema обязательные таблицы GeoPackage (OGC 12-128r19, приложение C)
+var schema = []string{
+	`CREATE TABLE gpkg_spatial_ref_sys (
+		srs_name TEXT NOT NULL,
+		srs_id INTEGER NOT NULL PRIMARY KEY,
+		organization TEXT NOT NULL,
+		organization_coordsys_id INTEGER NOT NULL,
+		definition TEXT NOT NULL,
+		description TEXT
+	)`,
+	`CREATE TABLE gpkg_contents (
+		table_name TEXT NOT NULL PRIMARY KEY,
+		data_type TEXT NOT NULL,
+		identifier TEXT UNIQUE,
+		description TEXT DEFAULT '',
+		last_change DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
+		min_x DOUBLE,
+		min_y DOUBLE,
+		max_x DOUBLE,
+		max_y DOUBLE,
+		srs_id INTEGER,
+		CONSTRAINT fk_gc_r_srs_id FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys(srs_id)
+	)`,
+	`CREATE TABLE gpkg_geometry_columns (
+		table_name TEXT NOT NULL,
+		column_name TEXT NOT NULL,
+		geometry_type_name TEXT NOT NULL,
+		srs_id INTEGER NOT NULL,
+		z TINYINT NOT NULL,
+		m TINYINT NOT NULL,
+		CONSTRAINT pk_geom_cols PRIMARY KEY (table_name, column_name),
+		CONSTRAINT uk_gc_table_name UNIQUE (table_name),
+		CONSTRAINT fk_gc_tn FOREIGN KEY (table_name) REFERENCES gpkg_contents(table_name),
+		CONSTRAINT fk_gc_srs FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys (srs_id)
+	)`,
+	`INSERT INTO gpkg_spatial_ref_sys VALUES
+		('WGS 84 geodetic', 4326, 'EPSG', 4326,
+		 'GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563,AUTHORITY["EPSG","7030"]],AUTHORITY["EPSG","6326"]],PRIMEM["Greenwich",0,AUTHORITY["EPSG","8901"]],UNIT["degree",0.0174532925199433,AUTHORITY["EPSG","9122"]],AXIS["Latitude",NORTH],AXIS["Longitude",EAST],AUTHORITY["EPSG","4326"]]',
+		 'longitude/latitude coordinates in decimal degrees on the WGS 84 spheroid'),
+		('Undefined cartesian SRS', -1, 'NONE', -1, 'undefined', 'undefined cartesian coordinate reference system'),
+		('Undefined geographic SRS', 0, 'NONE', 0, 'undefined', 'undefined geographic coordinate reference system')`,
+}
+
+// jsonMimeType тип содержимого столбцов с объектами и массивами в gpkg_data_columns
+const jsonMimeType = "application/json"
+
+// dataColumnsSchema таблицы расширения gpkg_schema с описанием столбцов (OGC 12-128r19, F.9)
+var dataColumnsSchema = []string{
+	`CREATE TABLE gpkg_extensions (
+		table_name TEXT,
+		column_name TEXT,
+		extension_name TEXT NOT NULL,
+		definition TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		CONSTRAINT ge_tce UNIQUE (table_name, column_name, extension_name)
+	)`,
+	`CREATE TABLE gpkg_data_columns (
+		table_name TEXT NOT NULL,
+		column_name TEXT NOT NULL,
+		name TEXT,
+		title TEXT,
+		description TEXT,
+		mime_type TEXT,
+		constraint_name TEXT,
+		CONSTRAINT pk_gdc PRIMARY KEY (table_name, column_name),
+		CONSTRAINT gdc_tn UNIQUE (table_name, name)
+	)`,
+	`CREATE TABLE gpkg_data_column_constraints (
+		constraint_name TEXT NOT NULL,
+		constraint_type TEXT NOT NULL,
+		value TEXT,
+		min NUMERIC,
+		min_is_inclusive BOOLEAN,
+		max NUMERIC,
+		max_is_inclusive BOOLEAN,
+		description TEXT,
+		CONSTRAINT gdcc_ntv UNIQUE (constraint_name, constraint_type, value)
+	)`,
+	`INSERT INTO gpkg_extensions VALUES
+		('gpkg_data_columns', NULL, 'gpkg_schema', 'http://www.geopackage.org/spec/#extension_schema', 'read-write'),
+		('gpkg_data_column_constraints', NULL, 'gpkg_schema', 'http://www.geopackage.org/spec/#extension_schema', 'read-write')`,
+}
+
+// createDataColumns создаёт таблицы описания столбцов, если их ещё нет
+func createDataColumns(tx *sql.Tx) error {
+	var exists int
+	if err := tx.QueryRow(`SELECT count(*) FROM sqlite_master WHERE name = 'gpkg_data_columns'`).Scan(&exists); err != nil {
+		return fmt.Errorf("не удалось создать таблицы описания столбцов: %w", err)
+	}
+	if exists > 0 {
+		return nil
+	}
+	for _, statement := range dataColumnsSchema {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("не удалось создать таблицы описания столбцов: %w", err)
+		}
+	}
+	return nil
+}
+
+// createSchema записывает идентификаторы GeoPackage и создаёт служебные таблицы
+func createSchema(tx *sql.Tx) error {
+	statements := append([]string{
+		fmt.Sprintf("PRAGMA application_id = %d", applicationID),
+		fmt.Sprintf("PRAGMA user_version = %d", userVersion),
+	}, schema...)
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("не удалось создать таблицы GeoPackage: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package gpx
+
+import "encoding/xml"
+
+// Структуры GPX 1.1; порядок полей задаёт порядок элементов, которого требует схема
+
+type gpxRoot struct {
+	XMLName   xml.Name   `xml:"gpx"`
+	XMLNS     string     `xml:"xmlns,attr"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Metadata  *gpxMeta   `xml:"metadata,omitempty"`
+	Waypoints []gpxPoint `xml:"wpt"`
+	Routes    []gpxRoute `xml:"rte"`
+	Tracks    []gpxTrack `xml:"trk"`
+}
+
+type gpxMeta struct {
+	Name string `xml:"name,omitempty"`
+}
+
+// gpxPoint путевая точка wpt, точка маршрута rtept или трека trkpt
+type gpxPoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Ele  string `xml:"ele,omitempty"`
+	Time string `xml:"time,omitempty"`
+	gpxInfo
+}
+
+// gpxInfo общие поля путевой точки, маршрута и трека
+type gpxInfo struct {
+	Name string `xml:"name,omitempty"`
+	Cmt  string `xml:"cmt,omitempty"`
+	Desc string `xml:"desc,omitempty"`
+	Sym  string `xml:"sym,omitempty"`
+	Type string `xml:"type,omitempty"`
+}
+
+// gpxRouteInfo поля маршрута и трека: у них нет sym, а type идёт после desc
+type gpxRouteInfo struct {
+	Name string `xml:"name,omitempty"`
+	Cmt  string `xml:"cmt,omitempty"`
+	Desc string `xml:"desc,omitempty"`
+	Type string `xml:"type,omitempty"`
+}
+
+type gpxRoute struct {
+	gpxRouteInfo
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	gpxRouteInfo
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
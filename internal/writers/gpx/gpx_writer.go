@@ -0,0 +1,208 @@
+// Package gpx записывает объекты в GPX 1.1 для загрузки в навигаторы Garmin и другие.
+//
+// Точки и мультиточки становятся путевыми точками wpt: название - name, описание - desc,
+// высота - ele (третья координата или свойство ele), свойство models.PropertyTime - time,
+// свойства sym, type и cmt - одноимённые элементы. Линии становятся треками trk
+// (мультилиния - треком из нескольких сегментов), а линии со свойством gpx_type: rte -
+// маршрутами. Время точек берётся из свойства models.PropertyCoordTimes.
+// Полигоны в GPX не записываются
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/writers"
+)
+
+// Свойства объекта, которые записываются в элементы GPX (их же заполняет GPX reader)
+const (
+	propertyElevation = "ele"
+	propertySymbol    = "sym"
+	propertyType      = "type"
+	propertyComment   = "cmt"
+	propertyKind      = "gpx_type"
+)
+
+// GPXWriter пишет объекты в документ GPX
+type GPXWriter struct {
+	waypoints []gpxPoint
+	routes    []gpxRoute
+	tracks    []gpxTrack
+	// Rejections объекты с недопустимой геометрией, пропущенные последним вызовом Write
+	writers.Rejections
+}
+
+// NewGPXWriter создаёт writer с пустым документом
+func NewGPXWriter() *GPXWriter {
+	return &GPXWriter{}
+}
+
+// Write добавляет объекты в документ. Цвет в GPX не записывается, параметр color нужен для интерфейса Writer
+func (w *GPXWriter) Write(data *[]models.CordsData, color ...string) error {
+	if data == nil || len(*data) == 0 {
+		return fmt.Errorf("нет данных для записи")
+	}
+	w.ResetRejected()
+
+	for i, item := range *data {
+		if item.Geometry == nil || item.Geometry.IsEmpty() {
+			fmt.Printf("⚠️  Объект %d ('%s') без геометрии пропущен\n", i+1, item.IconCaption)
+			continue
+		}
+		if !w.CheckGeometry(i+1, item) {
+			continue
+		}
+		if skipped := w.add(item, item.Geometry); skipped > 0 {
+			fmt.Printf("⚠️  Объект %d ('%s'): полигоны не записываются в GPX (%d)\n", i+1, item.IconCaption, skipped)
+		}
+	}
+	return nil
+}
+
+// add записывает геометрию объекта и возвращает число пропущенных полигонов
+func (w *GPXWriter) add(item models.CordsData, geometry models.Geometry) int {
+	switch g := geometry.(type) {
+	case *models.PointGeometry:
+		w.waypoints = append(w.waypoints, newWaypoint(item, g.Coordinates))
+	case *models.MultiPointGeometry:
+		for _, pos := range g.Coordinates {
+			w.waypoints = append(w.waypoints, newWaypoint(item, pos))
+		}
+	case *models.LineStringGeometry:
+		w.addLines(item, [][][]float64{g.Coordinates})
+	case *models.MultiLineStringGeometry:
+		w.addLines(item, g.Coordinates)
+	case *models.PolygonGeometry:
+		return 1
+	case *models.MultiPolygonGeometry:
+		return len(g.Coordinates)
+	case *models.CollectionGeometry:
+		skipped := 0
+		for _, member := range g.Geometries {
+			skipped += w.add(item, member)
+		}
+		return skipped
+	}
+	return 0
+}
+
+// addLines записывает линии трека или маршрута; маршрут всегда из одной линии,
+// поэтому мультилиния с gpx_type: rte записывается несколькими маршрутами
+func (w *GPXWriter) addLines(item models.CordsData, lines [][][]float64) {
+	info := gpxRouteInfo{
+		Name: item.IconCaption,
+		Cmt:  stringProperty(item, propertyComment),
+		Desc: item.Description,
+		Type: stringProperty(item, propertyType),
+	}
+
+	count := 0
+	for _, line := range lines {
+		count += len(line)
+	}
+	times, _ := item.Properties[models.PropertyCoordTimes].([]any)
+	if len(times) != count {
+		times = nil
+	}
+
+	var segments []gpxSegment
+	offset := 0
+	for _, line := range lines {
+		segment := gpxSegment{}
+		for i, pos := range line {
+			point := newPoint(pos)
+			if times != nil {
+				point.Time, _ = times[offset+i].(string)
+			}
+			segment.Points = append(segment.Points, point)
+		}
+		offset += len(line)
+		segments = append(segments, segment)
+	}
+
+	if stringProperty(item, propertyKind) == "rte" {
+		for _, segment := range segments {
+			w.routes = append(w.routes, gpxRoute{gpxRouteInfo: info, Points: segment.Points})
+		}
+		return
+	}
+	w.tracks = append(w.tracks, gpxTrack{gpxRouteInfo: info, Segments: segments})
+}
+
+// Save сохраняет документ в файл .gpx
+func (w *GPXWriter) Save(path string) error {
+	root := gpxRoot{
+		XMLNS:     "http://www.topografix.com/GPX/1/1",
+		Version:   "1.1",
+		Creator:   "jgeo-excel",
+		Metadata:  &gpxMeta{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))},
+		Waypoints: w.waypoints,
+		Routes:    w.routes,
+		Tracks:    w.tracks,
+	}
+	body, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сформировать GPX: %w", err)
+	}
+	data := append([]byte(xml.Header), body...)
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("не удалось сохранить GPX файл: %w", err)
+	}
+	return nil
+}
+
+// Close освобождает документ
+func (w *GPXWriter) Close() error {
+	w.waypoints, w.routes, w.tracks = nil, nil, nil
+	return nil
+}
+
+// newWaypoint создаёт путевую точку: высота из координаты, иначе из свойства ele
+func newWaypoint(item models.CordsData, pos []float64) gpxPoint {
+	point := newPoint(pos)
+	if point.Ele == "" {
+		point.Ele = stringProperty(item, propertyElevation)
+	}
+	point.Time = stringProperty(item, models.PropertyTime)
+	point.gpxInfo = gpxInfo{
+		Name: item.IconCaption,
+		Cmt:  stringProperty(item, propertyComment),
+		Desc: item.Description,
+		Sym:  stringProperty(item, propertySymbol),
+		Type: stringProperty(item, propertyType),
+	}
+	return point
+}
+
+// newPoint создаёт точку из координаты [долгота, широта(, высота)]
+func newPoint(pos []float64) gpxPoint {
+	point := gpxPoint{
+		Lat: strconv.FormatFloat(pos[1], 'f', -1, 64),
+		Lon: strconv.FormatFloat(pos[0], 'f', -1, 64),
+	}
+	if len(pos) > 2 {
+		point.Ele = strconv.FormatFloat(pos[2], 'f', -1, 64)
+	}
+	return point
+}
+
+// stringProperty возвращает свойство объекта текстом; пустая строка - свойства нет
+func stringProperty(item models.CordsData, key string) string {
+	switch v := item.Properties[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,147 @@
+package kml
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Структуры элементов KML 2.2, которые создаёт KMLWriter
+
+type kmlRoot struct {
+	XMLName  xml.Name    `xml:"kml"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	XMLNSGX  string      `xml:"xmlns:gx,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name         string           `xml:"name"`
+	Styles       []*kmlStyle      `xml:"Style"`
+	ExtendedData *kmlExtendedData `xml:"ExtendedData"`
+	Features     []any
+}
+
+// kmlFolder папка; Features - Placemark и вложенные Folder в порядке появления объектов,
+// имя элемента берётся из XMLName
+type kmlFolder struct {
+	XMLName  xml.Name `xml:"Folder"`
+	Name     string   `xml:"name,omitempty"`
+	Features []any
+	// folders вложенные папки для поиска по названию
+	folders []*kmlFolder
+}
+
+// folder возвращает вложенную папку по пути "Маршруты/2024", создавая недостающие;
+// пустой путь - сама папка
+func (f *kmlFolder) folder(path string) *kmlFolder {
+	current := f
+	for _, name := range strings.Split(path, "/") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		var next *kmlFolder
+		for _, child := range current.folders {
+			if child.Name == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			next = &kmlFolder{Name: name}
+			current.folders = append(current.folders, next)
+			current.Features = append(current.Features, next)
+		}
+		current = next
+	}
+	return current
+}
+
+type kmlPlacemark struct {
+	XMLName      xml.Name         `xml:"Placemark"`
+	ID           string           `xml:"id,attr,omitempty"`
+	Name         string           `xml:"name,omitempty"`
+	Description  string           `xml:"description,omitempty"`
+	StyleURL     string           `xml:"styleUrl,omitempty"`
+	TimeStamp    *kmlTimeStamp    `xml:"TimeStamp"`
+	ExtendedData *kmlExtendedData `xml:"ExtendedData"`
+	*kmlGeometry
+}
+
+// kmlGeometry одна из геометрий Placemark
+type kmlGeometry struct {
+	Point      *kmlCoordinates   `xml:"Point"`
+	LineString *kmlCoordinates   `xml:"LineString"`
+	Polygon    *kmlPolygon       `xml:"Polygon"`
+	Multi      *kmlMultiGeometry `xml:"MultiGeometry"`
+	Track      *kmlTrack         `xml:"gx:Track"`
+}
+
+type kmlMultiGeometry struct {
+	Points      []kmlCoordinates   `xml:"Point"`
+	LineStrings []kmlCoordinates   `xml:"LineString"`
+	Polygons    []kmlPolygon       `xml:"Polygon"`
+	Multi       []kmlMultiGeometry `xml:"MultiGeometry"`
+	Tracks      []kmlTrack         `xml:"gx:Track"`
+}
+
+// add добавляет геометрию в MultiGeometry
+func (m *kmlMultiGeometry) add(g *kmlGeometry) {
+	switch {
+	case g == nil:
+	case g.Point != nil:
+		m.Points = append(m.Points, *g.Point)
+	case g.LineString != nil:
+		m.LineStrings = append(m.LineStrings, *g.LineString)
+	case g.Polygon != nil:
+		m.Polygons = append(m.Polygons, *g.Polygon)
+	case g.Multi != nil:
+		m.Multi = append(m.Multi, *g.Multi)
+	case g.Track != nil:
+		m.Tracks = append(m.Tracks, *g.Track)
+	}
+}
+
+type kmlCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlPolygon struct {
+	Outer kmlBoundary   `xml:"outerBoundaryIs"`
+	Inner []kmlBoundary `xml:"innerBoundaryIs"`
+}
+
+type kmlBoundary struct {
+	Ring kmlCoordinates `xml:"LinearRing"`
+}
+
+// kmlTrack gx:Track: время точек when и координаты gx:coord "долгота широта высота"
+type kmlTrack struct {
+	When   []string `xml:"when"`
+	Coords []string `xml:"gx:coord"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlExtendedData struct {
+	Data []kmlData `xml:"Data"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlStyle struct {
+	ID        string         `xml:"id,attr,omitempty"`
+	IconStyle *kmlColorStyle `xml:"IconStyle"`
+	LineStyle *kmlColorStyle `xml:"LineStyle"`
+	PolyStyle *kmlColorStyle `xml:"PolyStyle"`
+}
+
+// kmlColorStyle IconStyle, LineStyle или PolyStyle
+type kmlColorStyle struct {
+	Color string  `xml:"color,omitempty"`
+	Width float64 `xml:"width,omitempty"`
+}
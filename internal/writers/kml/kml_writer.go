@@ -0,0 +1,328 @@
+// Package kml записывает объекты в KML или KMZ для Google Earth, OruxMaps и т.п.
+//
+// Каждый объект становится Placemark в порядке входных данных: название - name, описание - description,
+// идентификатор - атрибут id (с префиксом kmlid.Prefix, если он начинается не с буквы),
+// свойства - ExtendedData, свойство models.PropertyTime - TimeStamp. Линия со временем точек
+// (models.PropertyCoordTimes) записывается треком gx:Track. Объекты со свойством models.PropertyFolder раскладываются
+// по вложенным папкам Folder. Оформление simplestyle (marker-color, stroke, fill и т.д.)
+// переводится в общие стили документа: одинаковое оформление - один Style
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/kmlid"
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/writers"
+)
+
+// KMLWriter пишет объекты в документ KML
+type KMLWriter struct {
+	root *kmlFolder
+	// styles общие стили документа по ключу оформления
+	styles     map[string]*kmlStyle
+	styleOrder []*kmlStyle
+	// prefixedIDs у части Placemark идентификатор записан с префиксом kmlid.Prefix
+	prefixedIDs bool
+	// Rejections объекты с недопустимой геометрией, пропущенные последним вызовом Write
+	writers.Rejections
+}
+
+// NewKMLWriter создаёт writer с пустым документом
+func NewKMLWriter() *KMLWriter {
+	return &KMLWriter{root: &kmlFolder{}, styles: make(map[string]*kmlStyle)}
+}
+
+// Write добавляет объекты в документ. Цвет color применяется к точкам без своего marker-color
+func (w *KMLWriter) Write(data *[]models.CordsData, color ...string) error {
+	if data == nil || len(*data) == 0 {
+		return fmt.Errorf("нет данных для записи")
+	}
+	w.ResetRejected()
+
+	for i, item := range *data {
+		if !w.CheckGeometry(i+1, item) {
+			continue
+		}
+
+		placemark := kmlPlacemark{
+			ID:          kmlid.Encode(item.ID),
+			Name:        item.IconCaption,
+			Description: item.Description,
+			kmlGeometry: newGeometry(item.Geometry),
+		}
+		if placemark.ID != item.ID {
+			w.prefixedIDs = true
+		}
+		skip := map[string]bool{models.PropertyFolder: true}
+		if track := newTrack(item.Geometry, item.Properties[models.PropertyCoordTimes]); track != nil {
+			placemark.kmlGeometry = &kmlGeometry{Track: track}
+			skip[models.PropertyCoordTimes] = true
+		}
+		if when, ok := item.Properties[models.PropertyTime].(string); ok && when != "" {
+			placemark.TimeStamp = &kmlTimeStamp{When: when}
+			skip[models.PropertyTime] = true
+		}
+
+		style := item.Style
+		if _, ok := style[models.StyleMarkerColor]; !ok && len(color) > 0 && color[0] != "" {
+			style = make(models.Style, len(item.Style)+1)
+			for key, value := range item.Style {
+				style[key] = value
+			}
+			style[models.StyleMarkerColor] = color[0]
+		}
+		if id := w.styleID(style, item.GeometryType()); id != "" {
+			placemark.StyleURL = "#" + id
+		}
+
+		folder := ""
+		if value, ok := item.Properties[models.PropertyFolder]; ok {
+			folder = fmt.Sprint(value)
+		}
+		keys := make([]string, 0, len(item.Properties))
+		for key := range item.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if skip[key] {
+				continue
+			}
+			if placemark.ExtendedData == nil {
+				placemark.ExtendedData = &kmlExtendedData{}
+			}
+			placemark.ExtendedData.Data = append(placemark.ExtendedData.Data, kmlData{Name: key, Value: propertyValue(item.Properties[key])})
+		}
+
+		target := w.root.folder(folder)
+		target.Features = append(target.Features, placemark)
+	}
+	return nil
+}
+
+// Save сохраняет документ в .kml или в архив .kmz (doc.kml), формат выбирается по расширению
+func (w *KMLWriter) Save(path string) error {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	document := kmlDocument{
+		Name:     name,
+		Styles:   w.styleOrder,
+		Features: w.root.Features,
+	}
+	// Отметка документа: по ней reader убирает префикс идентификаторов
+	if w.prefixedIDs {
+		document.ExtendedData = &kmlExtendedData{Data: []kmlData{{Name: kmlid.Marker, Value: kmlid.Prefix}}}
+	}
+	body, err := xml.MarshalIndent(kmlRoot{XMLNS: "http://www.opengis.net/kml/2.2", XMLNSGX: "http://www.google.com/kml/ext/2.2", Document: document}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сформировать KML: %w", err)
+	}
+	data := append([]byte(xml.Header), body...)
+
+	if strings.EqualFold(filepath.Ext(path), ".kmz") {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		file, err := archive.Create("doc.kml")
+		if err != nil {
+			return fmt.Errorf("не удалось создать архив KMZ: %w", err)
+		}
+		if _, err := file.Write(data); err != nil {
+			return fmt.Errorf("не удалось создать архив KMZ: %w", err)
+		}
+		if err := archive.Close(); err != nil {
+			return fmt.Errorf("не удалось создать архив KMZ: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("не удалось сохранить KML файл: %w", err)
+	}
+	return nil
+}
+
+// Close освобождает документ
+func (w *KMLWriter) Close() error {
+	w.root = nil
+	w.styles, w.styleOrder = nil, nil
+	w.prefixedIDs = false
+	return nil
+}
+
+// styleID возвращает идентификатор общего стиля для оформления объекта; пустая строка - оформления нет
+func (w *KMLWriter) styleID(style models.Style, geometryType string) string {
+	s := &kmlStyle{}
+	if color, ok := kmlColor(style[models.StyleMarkerColor], nil); ok && models.StyleAppliesTo(models.StyleMarkerColor, geometryType) {
+		s.IconStyle = &kmlColorStyle{Color: color}
+	}
+	if models.StyleAppliesTo(models.StyleStroke, geometryType) {
+		color, hasColor := kmlColor(style[models.StyleStroke], style[models.StyleStrokeOpacity])
+		width, hasWidth := number(style[models.StyleStrokeWidth])
+		if hasColor || hasWidth {
+			s.LineStyle = &kmlColorStyle{Color: color, Width: width}
+		}
+	}
+	if color, ok := kmlColor(style[models.StyleFill], style[models.StyleFillOpacity]); ok && models.StyleAppliesTo(models.StyleFill, geometryType) {
+		s.PolyStyle = &kmlColorStyle{Color: color}
+	}
+	if s.IconStyle == nil && s.LineStyle == nil && s.PolyStyle == nil {
+		return ""
+	}
+
+	key, _ := xml.Marshal(s)
+	if existing, ok := w.styles[string(key)]; ok {
+		return existing.ID
+	}
+	s.ID = fmt.Sprintf("style%d", len(w.styleOrder)+1)
+	w.styles[string(key)] = s
+	w.styleOrder = append(w.styleOrder, s)
+	return s.ID
+}
+
+// kmlColor переводит цвет #RRGGBB и непрозрачность (0..1, по умолчанию 1) в цвет KML aabbggrr
+func kmlColor(color, opacity any) (string, bool) {
+	text, ok := color.(string)
+	if !ok {
+		return "", false
+	}
+	hex := strings.TrimPrefix(strings.TrimSpace(text), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 && len(hex) != 8 {
+		return "", false
+	}
+	if _, err := strconv.ParseUint(hex, 16, 32); err != nil {
+		return "", false
+	}
+
+	alpha := "ff"
+	if len(hex) == 8 {
+		alpha = hex[6:8]
+	}
+	if value, ok := number(opacity); ok {
+		alpha = fmt.Sprintf("%02x", int(min(max(value, 0), 1)*255+0.5))
+	}
+	return strings.ToLower(alpha + hex[4:6] + hex[2:4] + hex[0:2]), true
+}
+
+// number приводит число или строку с числом к float64
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		num, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return num, err == nil
+	}
+	return 0, false
+}
+
+// propertyValue записывает значение свойства текстом: числа - без экспоненты,
+// вложенные объекты и массивы - как JSON
+func propertyValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// newGeometry переводит геометрию объекта в элементы KML; nil - Placemark без геометрии
+func newGeometry(geometry models.Geometry) *kmlGeometry {
+	if geometry == nil || geometry.IsEmpty() {
+		return nil
+	}
+
+	switch g := geometry.(type) {
+	case *models.PointGeometry:
+		return &kmlGeometry{Point: &kmlCoordinates{Coordinates: formatPositions([][]float64{g.Coordinates})}}
+	case *models.LineStringGeometry:
+		return &kmlGeometry{LineString: &kmlCoordinates{Coordinates: formatPositions(g.Coordinates)}}
+	case *models.PolygonGeometry:
+		return &kmlGeometry{Polygon: newPolygon(g.Coordinates)}
+	}
+
+	multi := &kmlMultiGeometry{}
+	switch g := geometry.(type) {
+	case *models.MultiPointGeometry:
+		for _, pos := range g.Coordinates {
+			multi.add(newGeometry(models.NewPointGeometry(pos[0], pos[1], pos[2:]...)))
+		}
+	case *models.MultiLineStringGeometry:
+		for _, line := range g.Coordinates {
+			multi.add(newGeometry(models.NewLineStringGeometry(line...)))
+		}
+	case *models.MultiPolygonGeometry:
+		for _, polygon := range g.Coordinates {
+			multi.add(newGeometry(models.NewPolygonGeometry(polygon...)))
+		}
+	case *models.CollectionGeometry:
+		for _, member := range g.Geometries {
+			multi.add(newGeometry(member))
+		}
+	}
+	return &kmlGeometry{Multi: multi}
+}
+
+// newTrack создаёт трек gx:Track, если у линии есть время каждой точки; иначе nil
+func newTrack(geometry models.Geometry, times any) *kmlTrack {
+	line, ok := geometry.(*models.LineStringGeometry)
+	values, _ := times.([]any)
+	if !ok || len(values) == 0 || len(values) != len(line.Coordinates) {
+		return nil
+	}
+
+	track := &kmlTrack{}
+	for i, pos := range line.Coordinates {
+		when, ok := values[i].(string)
+		if !ok {
+			return nil
+		}
+		track.When = append(track.When, when)
+		track.Coords = append(track.Coords, strings.ReplaceAll(formatPositions([][]float64{pos}), ",", " "))
+	}
+	return track
+}
+
+// newPolygon создаёт полигон: первый контур внешний, остальные - отверстия
+func newPolygon(rings [][][]float64) *kmlPolygon {
+	polygon := &kmlPolygon{Outer: kmlBoundary{Ring: kmlCoordinates{Coordinates: formatPositions(rings[0])}}}
+	for _, ring := range rings[1:] {
+		polygon.Inner = append(polygon.Inner, kmlBoundary{Ring: kmlCoordinates{Coordinates: formatPositions(ring)}})
+	}
+	return polygon
+}
+
+// formatPositions записывает координаты в формате KML "долгота,широта[,высота] ..."
+func formatPositions(positions [][]float64) string {
+	tuples := make([]string, 0, len(positions))
+	for _, pos := range positions {
+		parts := make([]string, 0, len(pos))
+		for _, v := range pos {
+			parts = append(parts, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		tuples = append(tuples, strings.Join(parts, ","))
+	}
+	return strings.Join(tuples, " ")
+}
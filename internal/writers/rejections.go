@@ -0,0 +1,50 @@
+package writers
+
+import (
+	"fmt"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// Rejections объекты с недопустимой геометрией, пропущенные последним вызовом Write.
+// Встраивается в writer: недопустимый объект пропускается с предупреждением, остальные записываются
+type Rejections struct {
+	// Rejected строки источника пропущенных объектов (models.RejectGeometry)
+	Rejected []models.RejectedRow
+}
+
+// RejectedRows возвращает строки источника объектов, пропущенных последним вызовом Write
+func (r *Rejections) RejectedRows() []models.RejectedRow {
+	return r.Rejected
+}
+
+// ResetRejected очищает список пропущенных объектов перед новым вызовом Write
+func (r *Rejections) ResetRejected() {
+	r.Rejected = nil
+}
+
+// CheckGeometry проверяет геометрию объекта num (с 1). Недопустимая геометрия выводится
+// предупреждением и запоминается по строкам источника; false - объект нужно пропустить.
+// Объект без геометрии проверку проходит
+func (r *Rejections) CheckGeometry(num int, item models.CordsData) bool {
+	if item.Geometry == nil {
+		return true
+	}
+	err := item.Geometry.Validate()
+	if err == nil {
+		return true
+	}
+
+	message := fmt.Sprintf("недопустимая геометрия: %v", err)
+	fmt.Printf("⚠️  Пропущен объект %d ('%s'): %s\n", num, item.IconCaption, message)
+	for _, row := range item.SourceRows() {
+		r.Rejected = append(r.Rejected, models.RejectedRow{Row: row, Reason: models.RejectGeometry, Message: message})
+	}
+	return false
+}
+
+// RejectingWriter writer, который пропускает объекты с недопустимой геометрией
+type RejectingWriter interface {
+	Writer
+	RejectedRows() []models.RejectedRow
+}
@@ -0,0 +1,249 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Ограничения формата dBase
+const (
+	dbfNameLen    = 10
+	dbfTextLen    = 254
+	dbfNumberLen  = 20
+	dbfLanguageID = 0xC9 // Russian Windows (Windows-1251)
+)
+
+// dbfField поле .dbf и функция, которая берёт его значение из объекта
+type dbfField struct {
+	name     []byte
+	kind     byte
+	length   int
+	decimals int
+	value    func(item models.CordsData) any
+}
+
+// encodeDBF записывает атрибуты объектов в таблицу dBase III в кодировке Windows-1251
+func encodeDBF(items []models.CordsData) ([]byte, error) {
+	encoder := encoding.ReplaceUnsupported(charmap.Windows1251.NewEncoder())
+	fields := dbfFields(items, encoder)
+
+	recordLen := 1
+	for _, field := range fields {
+		recordLen += field.length
+	}
+	headerLen := 32 + 32*len(fields) + 1
+	if recordLen > 0xFFFF {
+		return nil, fmt.Errorf("слишком много атрибутов для .dbf: длина записи %d байт", recordLen)
+	}
+
+	var buf bytes.Buffer
+	now := time.Now()
+	buf.Write([]byte{0x03, byte(now.Year() - 1900), byte(now.Month()), byte(now.Day())})
+	binary.Write(&buf, binary.LittleEndian, uint32(len(items)))
+	binary.Write(&buf, binary.LittleEndian, uint16(headerLen))
+	binary.Write(&buf, binary.LittleEndian, uint16(recordLen))
+	reserved := make([]byte, 20)
+	reserved[17] = dbfLanguageID // байт 29 заголовка
+	buf.Write(reserved)
+
+	for _, field := range fields {
+		desc := make([]byte, 32)
+		copy(desc[:11], field.name)
+		desc[11] = field.kind
+		desc[16] = byte(field.length)
+		desc[17] = byte(field.decimals)
+		buf.Write(desc)
+	}
+	buf.WriteByte(0x0D)
+
+	truncated := make(map[string]bool)
+	for _, item := range items {
+		buf.WriteByte(' ')
+		for _, field := range fields {
+			text := fieldText(field, field.value(item), encoder)
+			if len(text) > field.length {
+				if !truncated[string(field.name)] {
+					fmt.Printf("⚠️  Значения поля %s обрезаны до %d байт\n", field.name, field.length)
+					truncated[string(field.name)] = true
+				}
+				text = text[:field.length]
+			}
+			pad := bytes.Repeat([]byte{' '}, field.length-len(text))
+			if field.kind == 'N' {
+				buf.Write(pad)
+				buf.Write(text)
+			} else {
+				buf.Write(text)
+				buf.Write(pad)
+			}
+		}
+	}
+	buf.WriteByte(0x1A)
+	return buf.Bytes(), nil
+}
+
+// builtinFields поля идентификатора, названия и описания. Reader шейп-файлов читает их
+// в поля объекта, поэтому свойства с такими именами получают суффикс, даже если поле не записано
+var builtinFields = []string{"id", "name", "descr"}
+
+// dbfFields подбирает поля таблицы: id, name и descr, если они заполнены хотя бы у одного объекта,
+// затем свойства в алфавитном порядке. Тип и ширина поля выбираются по значениям
+func dbfFields(items []models.CordsData, encoder *encoding.Encoder) []dbfField {
+	var fields []dbfField
+	used := make(map[string]bool)
+	add := func(key string, value func(item models.CordsData) any) {
+		values := make([]any, 0, len(items))
+		for _, item := range items {
+			if v := value(item); v != nil && v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return
+		}
+		field := fieldType(values, encoder)
+		field.name = fieldName(key, encoder, used)
+		field.value = value
+		fields = append(fields, field)
+	}
+
+	add("id", func(item models.CordsData) any { return item.ID })
+	add("name", func(item models.CordsData) any { return item.IconCaption })
+	add("descr", func(item models.CordsData) any { return item.Description })
+	for _, name := range builtinFields {
+		used[strings.ToUpper(name)] = true
+	}
+
+	keys := make(map[string]bool)
+	for _, item := range items {
+		for key := range item.Properties {
+			keys[key] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		add(key, func(item models.CordsData) any { return item.Properties[key] })
+	}
+	return fields
+}
+
+// fieldType выбирает тип поля: числа - N, логические - L, остальное - текст C
+func fieldType(values []any, encoder *encoding.Encoder) dbfField {
+	numbers, bools := true, true
+	intDigits, decimals := 1, 0
+	for _, value := range values {
+		_, isBool := value.(bool)
+		bools = bools && isBool
+		num, isNumber := value.(float64)
+		numbers = numbers && isNumber
+		if isNumber {
+			text := strconv.FormatFloat(num, 'f', -1, 64)
+			whole, fraction, _ := strings.Cut(text, ".")
+			intDigits = max(intDigits, len(whole))
+			decimals = max(decimals, len(fraction))
+		}
+	}
+
+	switch {
+	case bools:
+		return dbfField{kind: 'L', length: 1}
+	case numbers:
+		length := intDigits
+		if decimals > 0 {
+			length += decimals + 1
+		}
+		if length <= dbfNumberLen {
+			return dbfField{kind: 'N', length: length, decimals: decimals}
+		}
+	}
+
+	length := 1
+	for _, value := range values {
+		length = max(length, len(encodeText(textValue(value), encoder)))
+	}
+	return dbfField{kind: 'C', length: min(length, dbfTextLen)}
+}
+
+// fieldName возвращает уникальное имя поля не длиннее 10 байт
+func fieldName(key string, encoder *encoding.Encoder, used map[string]bool) []byte {
+	name := encodeText(key, encoder)
+	if len(name) > dbfNameLen {
+		name = name[:dbfNameLen]
+	}
+	for i := 1; used[upperASCII(name)]; i++ {
+		suffix := fmt.Sprintf("_%d", i)
+		base := encodeText(key, encoder)
+		name = append(base[:min(len(base), dbfNameLen-len(suffix))], suffix...)
+	}
+	used[upperASCII(name)] = true
+	return name
+}
+
+// upperASCII приводит латинские буквы имени поля к верхнему регистру; остальные байты
+// (в том числе русские буквы в Windows-1251) не меняются
+func upperASCII(name []byte) string {
+	result := make([]byte, len(name))
+	for i, b := range name {
+		if b >= 'a' && b <= 'z' {
+			b -= 'a' - 'A'
+		}
+		result[i] = b
+	}
+	return string(result)
+}
+
+// fieldText записывает значение в поле; пустое значение - пробелы
+func fieldText(field dbfField, value any, encoder *encoding.Encoder) []byte {
+	if value == nil {
+		return nil
+	}
+	switch field.kind {
+	case 'L':
+		if v, _ := value.(bool); v {
+			return []byte("T")
+		}
+		return []byte("F")
+	case 'N':
+		num, _ := value.(float64)
+		return []byte(strconv.FormatFloat(num, 'f', field.decimals, 64))
+	}
+	return encodeText(textValue(value), encoder)
+}
+
+// textValue приводит значение свойства к тексту: вложенные объекты и массивы - как JSON
+func textValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any, []any:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// encodeText переводит текст в Windows-1251; символы вне кодировки заменяются
+func encodeText(text string, encoder *encoding.Encoder) []byte {
+	data, err := encoder.Bytes([]byte(text))
+	if err != nil {
+		return []byte(text)
+	}
+	return data
+}
@@ -0,0 +1,183 @@
+// Package shapefile записывает объекты в шейп-файлы ESRI (.shp, .shx, .dbf, .prj, .cpg) или zip-архив с ними.
+//
+// Шейп-файл хранит фигуры одного типа, поэтому точки, мультиточки, линии и полигоны записываются
+// в отдельные слои: имя_points, имя_multipoints, имя_lines и имя_polygons (если тип один - просто имя).
+// Мультилинии и мультиполигоны записываются в слои линий и полигонов, а части коллекций - в слои
+// своих типов с атрибутами объекта. Идентификатор, название и описание становятся полями id, name
+// и descr, свойства - остальными полями .dbf (имена до 10 символов). Атрибуты записываются
+// в Windows-1251 (код языка в заголовке .dbf и файл .cpg), координаты - в WGS 84 (.prj)
+package shapefile
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+	"github.com/rmay1er/jgeo-excel/internal/writers"
+)
+
+// wgs84PRJ описание WGS 84 для файла .prj в виде, который понимают ArcGIS, QGIS и MapInfo
+const wgs84PRJ = `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`
+
+// Слои шейп-файла по типу фигур
+const (
+	layerPoints      = "points"
+	layerMultiPoints = "multipoints"
+	layerLines       = "lines"
+	layerPolygons    = "polygons"
+)
+
+// ShapefileWriter собирает объекты и записывает их слоями по типу геометрии
+type ShapefileWriter struct {
+	layers map[string]*layer
+	// Rejections объекты с недопустимой геометрией, пропущенные последним вызовом Write
+	writers.Rejections
+}
+
+// layer объекты одного слоя: геометрия записи и объект, из которого берутся атрибуты
+type layer struct {
+	geometries []models.Geometry
+	items      []models.CordsData
+}
+
+// NewShapefileWriter создаёт writer без объектов
+func NewShapefileWriter() *ShapefileWriter {
+	return &ShapefileWriter{layers: make(map[string]*layer)}
+}
+
+// Write добавляет объекты. Оформление в шейп-файл не записывается, параметр color нужен для интерфейса Writer
+func (w *ShapefileWriter) Write(data *[]models.CordsData, color ...string) error {
+	if data == nil || len(*data) == 0 {
+		return fmt.Errorf("нет данных для записи")
+	}
+	w.ResetRejected()
+
+	for i, item := range *data {
+		if item.Geometry == nil || item.Geometry.IsEmpty() {
+			fmt.Printf("⚠️  Объект %d ('%s') без геометрии пропущен\n", i+1, item.IconCaption)
+			continue
+		}
+		if !w.CheckGeometry(i+1, item) {
+			continue
+		}
+		w.add(item, item.Geometry)
+	}
+	return nil
+}
+
+// add записывает геометрию в слой её типа; части коллекции - каждую в свой слой
+func (w *ShapefileWriter) add(item models.CordsData, geometry models.Geometry) {
+	name := ""
+	switch g := geometry.(type) {
+	case *models.PointGeometry:
+		name = layerPoints
+	case *models.MultiPointGeometry:
+		name = layerMultiPoints
+	case *models.LineStringGeometry, *models.MultiLineStringGeometry:
+		name = layerLines
+	case *models.PolygonGeometry, *models.MultiPolygonGeometry:
+		name = layerPolygons
+	case *models.CollectionGeometry:
+		for _, member := range g.Geometries {
+			w.add(item, member)
+		}
+		return
+	}
+
+	l, ok := w.layers[name]
+	if !ok {
+		l = &layer{}
+		w.layers[name] = l
+	}
+	l.geometries = append(l.geometries, geometry)
+	l.items = append(l.items, item)
+}
+
+// Save записывает слои рядом с path (.shp) или в zip-архив (.zip)
+func (w *ShapefileWriter) Save(path string) error {
+	var names []string
+	for _, name := range []string{layerPoints, layerMultiPoints, layerLines, layerPolygons} {
+		if _, ok := w.layers[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("нет объектов с геометрией для шейп-файла")
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	files := make(map[string][]byte)
+	var order []string
+	for _, name := range names {
+		base := stem
+		if len(names) > 1 {
+			base = stem + "_" + name
+		}
+		layerFiles, err := w.layers[name].encode()
+		if err != nil {
+			return fmt.Errorf("слой %s: %w", base, err)
+		}
+		for _, ext := range []string{".shp", ".shx", ".dbf", ".prj", ".cpg"} {
+			files[base+ext] = layerFiles[ext]
+			order = append(order, base+ext)
+		}
+	}
+	if len(names) > 1 {
+		fmt.Printf("🗂️  Слои шейп-файла: %s\n", strings.Join(names, ", "))
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for _, name := range order {
+			file, err := archive.Create(name)
+			if err != nil {
+				return fmt.Errorf("не удалось создать архив: %w", err)
+			}
+			if _, err := file.Write(files[name]); err != nil {
+				return fmt.Errorf("не удалось создать архив: %w", err)
+			}
+		}
+		if err := archive.Close(); err != nil {
+			return fmt.Errorf("не удалось создать архив: %w", err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("не удалось сохранить архив шейп-файла: %w", err)
+		}
+		return nil
+	}
+
+	dir := filepath.Dir(path)
+	for _, name := range order {
+		if err := os.WriteFile(filepath.Join(dir, name), files[name], 0644); err != nil {
+			return fmt.Errorf("не удалось сохранить %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Close освобождает собранные объекты
+func (w *ShapefileWriter) Close() error {
+	w.layers = nil
+	return nil
+}
+
+// encode возвращает содержимое файлов слоя по расширению
+func (l *layer) encode() (map[string][]byte, error) {
+	shp, shx := encodeSHP(l.geometries)
+	dbf, err := encodeDBF(l.items)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		".shp": shp,
+		".shx": shx,
+		".dbf": dbf,
+		".prj": []byte(wgs84PRJ),
+		".cpg": []byte("1251"),
+	}, nil
+}
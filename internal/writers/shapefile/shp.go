@@ -0,0 +1,206 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/rmay1er/jgeo-excel/internal/models"
+)
+
+// Типы фигур .shp
+const (
+	shapePoint       = 1
+	shapePolyLine    = 3
+	shapePolygon     = 5
+	shapeMultiPoint  = 8
+	shapePointZ      = 11
+	shapePolyLineZ   = 13
+	shapePolygonZ    = 15
+	shapeMultiPointZ = 18
+)
+
+// bbox охват координат X, Y и Z
+type bbox struct {
+	minX, minY, maxX, maxY, minZ, maxZ float64
+}
+
+func newBBox() bbox {
+	return bbox{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1), math.Inf(1), math.Inf(-1)}
+}
+
+func (b *bbox) add(x, y, z float64) {
+	b.minX, b.maxX = min(b.minX, x), max(b.maxX, x)
+	b.minY, b.maxY = min(b.minY, y), max(b.maxY, y)
+	b.minZ, b.maxZ = min(b.minZ, z), max(b.maxZ, z)
+}
+
+func (b *bbox) merge(other bbox) {
+	b.add(other.minX, other.minY, other.minZ)
+	b.add(other.maxX, other.maxY, other.maxZ)
+}
+
+// shapeType выбирает тип фигур слоя и вариант с Z, если хотя бы у одной координаты есть высота
+func shapeType(geometries []models.Geometry) int {
+	hasZ := false
+	typ := shapePoint
+	for _, geometry := range geometries {
+		models.MapPositions(geometry, func(pos []float64) []float64 {
+			hasZ = hasZ || len(pos) > 2
+			return pos
+		})
+		switch geometry.(type) {
+		case *models.MultiPointGeometry:
+			typ = shapeMultiPoint
+		case *models.LineStringGeometry, *models.MultiLineStringGeometry:
+			typ = shapePolyLine
+		case *models.PolygonGeometry, *models.MultiPolygonGeometry:
+			typ = shapePolygon
+		}
+	}
+	if hasZ {
+		typ += 10
+	}
+	return typ
+}
+
+// encodeSHP записывает фигуры в .shp и индекс .shx
+func encodeSHP(geometries []models.Geometry) ([]byte, []byte) {
+	typ := shapeType(geometries)
+	var records bytes.Buffer
+	var index bytes.Buffer
+	total := newBBox()
+
+	for i, geometry := range geometries {
+		content, box := encodeShape(typ, geometry)
+		total.merge(box)
+
+		offset := shpHeaderSize + records.Len()
+		binary.Write(&index, binary.BigEndian, []int32{int32(offset / 2), int32(len(content) / 2)})
+		binary.Write(&records, binary.BigEndian, []int32{int32(i + 1), int32(len(content) / 2)})
+		records.Write(content)
+	}
+	if math.IsInf(total.minZ, 1) || typ < shapePointZ {
+		total.minZ, total.maxZ = 0, 0
+	}
+
+	shp := append(shpHeader(typ, shpHeaderSize+records.Len(), total), records.Bytes()...)
+	shx := append(shpHeader(typ, shpHeaderSize+index.Len(), total), index.Bytes()...)
+	return shp, shx
+}
+
+// shpHeaderSize размер заголовка .shp и .shx
+const shpHeaderSize = 100
+
+// shpHeader возвращает заголовок .shp или .shx длиной size байт
+func shpHeader(typ, size int, box bbox) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []int32{9994, 0, 0, 0, 0, 0, int32(size / 2)})
+	binary.Write(&buf, binary.LittleEndian, []int32{1000, int32(typ)})
+	binary.Write(&buf, binary.LittleEndian, []float64{box.minX, box.minY, box.maxX, box.maxY, box.minZ, box.maxZ, 0, 0})
+	return buf.Bytes()
+}
+
+// encodeShape записывает одну фигуру и возвращает её охват
+func encodeShape(typ int, geometry models.Geometry) ([]byte, bbox) {
+	var parts [][][]float64
+	switch g := geometry.(type) {
+	case *models.PointGeometry:
+		parts = [][][]float64{{g.Coordinates}}
+	case *models.MultiPointGeometry:
+		parts = [][][]float64{g.Coordinates}
+	case *models.LineStringGeometry:
+		parts = [][][]float64{g.Coordinates}
+	case *models.MultiLineStringGeometry:
+		parts = g.Coordinates
+	case *models.PolygonGeometry:
+		parts = shapeRings(g.Coordinates)
+	case *models.MultiPolygonGeometry:
+		for _, polygon := range g.Coordinates {
+			parts = append(parts, shapeRings(polygon)...)
+		}
+	}
+
+	var points [][]float64
+	box := newBBox()
+	for _, part := range parts {
+		for _, pos := range part {
+			point := []float64{pos[0], pos[1], 0}
+			if len(pos) > 2 {
+				point[2] = pos[2]
+			}
+			points = append(points, point)
+			box.add(point[0], point[1], point[2])
+		}
+	}
+
+	var buf bytes.Buffer
+	le := func(values ...any) {
+		for _, v := range values {
+			binary.Write(&buf, binary.LittleEndian, v)
+		}
+	}
+	le(int32(typ))
+	hasZ := typ >= shapePointZ
+
+	switch typ {
+	case shapePoint, shapePointZ:
+		le(points[0][0], points[0][1])
+		if hasZ {
+			le(points[0][2], 0.0)
+		}
+		return buf.Bytes(), box
+	case shapeMultiPoint, shapeMultiPointZ:
+		le(box.minX, box.minY, box.maxX, box.maxY, int32(len(points)))
+	default:
+		le(box.minX, box.minY, box.maxX, box.maxY, int32(len(parts)), int32(len(points)))
+		start := 0
+		for _, part := range parts {
+			le(int32(start))
+			start += len(part)
+		}
+	}
+	for _, point := range points {
+		le(point[0], point[1])
+	}
+	if hasZ {
+		le(box.minZ, box.maxZ)
+		for _, point := range points {
+			le(point[2])
+		}
+	}
+	return buf.Bytes(), box
+}
+
+// shapeRings переводит контуры полигона GeoJSON в контуры шейп-файла:
+// внешний - по часовой стрелке, отверстия - против неё
+func shapeRings(rings [][][]float64) [][][]float64 {
+	result := make([][][]float64, len(rings))
+	for i, ring := range rings {
+		clockwise := signedArea(ring) < 0
+		if (i == 0) != clockwise {
+			ring = reversed(ring)
+		}
+		result[i] = ring
+	}
+	return result
+}
+
+// signedArea возвращает удвоенную площадь контура со знаком: больше нуля - против часовой стрелки
+func signedArea(ring [][]float64) float64 {
+	area := 0.0
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		area += ring[i][0]*ring[j][1] - ring[j][0]*ring[i][1]
+	}
+	return area
+}
+
+// reversed возвращает контур в обратном порядке точек
+func reversed(ring [][]float64) [][]float64 {
+	result := make([][]float64, len(ring))
+	for i, pos := range ring {
+		result[len(ring)-1-i] = pos
+	}
+	return result
+}